@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// newFileMode is the mode new output files are created with, before the
+// umask is applied, as with os.WriteFile.
+const newFileMode fs.FileMode = 0o666
+
+func writeFile(path string, data []byte) error {
+	var (
+		mode      fs.FileMode
+		replacing bool
+	)
+
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if fi, err := os.Stat(path); err == nil {
+		if existing, err := os.ReadFile(path); err != nil {
+			return err
+		} else if bytes.Equal(existing, data) {
+			return nil
+		}
+
+		mode = fi.Mode().Perm()
+		replacing = true
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	f, err := createTemp(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+
+	if err := writeTemp(f, data, mode, replacing); err != nil {
+		os.Remove(f.Name())
+
+		return err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+
+		return err
+	}
+
+	return nil
+}
+
+// createTemp creates a new file in the given directory, with the given prefix
+// and a random suffix. Unlike os.CreateTemp, the file is created with
+// newFileMode, so that the umask is honoured.
+func createTemp(dir, prefix string) (*os.File, error) {
+	for {
+		f, err := os.OpenFile(filepath.Join(dir, prefix+strconv.FormatUint(rand.Uint64(), 36)), os.O_RDWR|os.O_CREATE|os.O_EXCL, newFileMode)
+		if !errors.Is(err, fs.ErrExist) {
+			return f, err
+		}
+	}
+}
+
+// writeTemp writes the data to the temporary file, setting its mode to that of
+// the file it is replacing.
+func writeTemp(f *os.File, data []byte, mode fs.FileMode, replacing bool) error {
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	if replacing {
+		if err := f.Chmod(mode); err != nil {
+			f.Close()
+
+			return err
+		}
+	}
+
+	return f.Close()
+}
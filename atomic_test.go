@@ -0,0 +1,102 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	umasked := filepath.Join(dir, "umask")
+
+	if err := os.WriteFile(umasked, nil, newFileMode); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fi, err := os.Stat(umasked)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for n, test := range [...]struct {
+		existing     string
+		mode         fs.FileMode
+		symlink      bool
+		data         string
+		expectedMode fs.FileMode
+		unchanged    bool
+	}{
+		{"", 0, false, "package a", fi.Mode(), false},
+		{"package a", 0o600, false, "package b", 0o600, false},
+		{"package a", 0o640, false, "package a", 0o640, true},
+		{"package a", 0o600, true, "package b", 0o600, false},
+	} {
+		tdir := filepath.Join(dir, string(rune('a'+n)))
+		path := filepath.Join(tdir, "a.go")
+		target := path
+
+		if err := os.Mkdir(tdir, 0o755); err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		}
+
+		if test.symlink {
+			target = filepath.Join(tdir, "b.go")
+
+			if err := os.Symlink("b.go", path); err != nil {
+				t.Fatalf("test %d: unexpected error: %s", n+1, err)
+			}
+		}
+
+		if test.mode != 0 {
+			if err := os.WriteFile(target, []byte(test.existing), test.mode); err != nil {
+				t.Fatalf("test %d: unexpected error: %s", n+1, err)
+			} else if err := os.Chmod(target, test.mode); err != nil {
+				t.Fatalf("test %d: unexpected error: %s", n+1, err)
+			} else if err := os.Chtimes(target, past, past); err != nil {
+				t.Fatalf("test %d: unexpected error: %s", n+1, err)
+			}
+		}
+
+		if err := writeFile(path, []byte(test.data)); err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		}
+
+		if data, err := os.ReadFile(path); err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		} else if string(data) != test.data {
+			t.Errorf("test %d: expecting data %q, got %q", n+1, test.data, data)
+		}
+
+		if fi, err := os.Lstat(target); err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		} else if fi.Mode() != test.expectedMode {
+			t.Errorf("test %d: expecting mode %s, got %s", n+1, test.expectedMode, fi.Mode())
+		} else if unchanged := fi.ModTime().Equal(past); unchanged != test.unchanged {
+			t.Errorf("test %d: expecting unchanged mod time to be %v, got %v", n+1, test.unchanged, unchanged)
+		}
+
+		if test.symlink {
+			if fi, err := os.Lstat(path); err != nil {
+				t.Fatalf("test %d: unexpected error: %s", n+1, err)
+			} else if fi.Mode()&fs.ModeSymlink == 0 {
+				t.Errorf("test %d: expecting symlink to be preserved", n+1)
+			}
+		}
+
+		expected := 1
+
+		if test.symlink {
+			expected = 2
+		}
+
+		if entries, err := os.ReadDir(tdir); err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		} else if len(entries) != expected {
+			t.Errorf("test %d: expecting %d files, got %d", n+1, expected, len(entries))
+		}
+	}
+}
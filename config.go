@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"vimagination.zapto.org/unsafe/generator"
+)
+
+type config struct {
+	Outputs []outputConfig `json:"outputs"`
+}
+
+type outputConfig struct {
+	Output     string            `json:"output"`
+	Package    string            `json:"package"`
+	Accessors  bool              `json:"accessors"`
+	Internal   bool              `json:"internal"`
+	Tags       string            `json:"tags"`
+	Naming     string            `json:"naming"`
+	Renames    map[string]string `json:"renames"`
+	Platforms  []string          `json:"platforms"`
+	GoVersions []string          `json:"go"`
+	Types      []typeConfig      `json:"types"`
+	Exclude    []string          `json:"exclude"`
+}
+
+type typeConfig struct {
+	Name      string `json:"name"`
+	Accessors *bool  `json:"accessors"`
+}
+
+func (t *typeConfig) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &t.Name); err == nil {
+		return nil
+	}
+
+	type plain typeConfig
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	dec.DisallowUnknownFields()
+
+	return dec.Decode((*plain)(t))
+}
+
+func loadConfig(path string) (*config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var c config
+
+	dec := json.NewDecoder(f)
+
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	outputs := make(map[string]struct{})
+
+	for n, output := range c.Outputs {
+		if output.Output == "" {
+			return nil, fmt.Errorf("%w: output %d", ErrNoOutput, n+1)
+		} else if _, ok := outputs[filepath.Clean(output.Output)]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateOutput, output.Output)
+		}
+
+		outputs[filepath.Clean(output.Output)] = struct{}{}
+
+		for _, typ := range output.Types {
+			if typ.Name == "" {
+				return nil, fmt.Errorf("%w: %s", ErrNoTypeName, output.Output)
+			}
+		}
+	}
+
+	return &c, nil
+}
+
+func (o *outputConfig) options() ([]string, []generator.Option, error) {
+	tagRewrites, err := parseTagRewrites(o.Tags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	namingOpts, err := namingOptions(o.Naming, o.Renames)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		typeNames []string
+		opts      = append([]generator.Option{generator.PackageName(o.Package), generator.TagRewrites(tagRewrites)}, namingOpts...)
+	)
+
+	if o.Accessors {
+		opts = append(opts, generator.Accessors())
+	}
+
+	if o.Internal {
+		opts = append(opts, generator.Internal())
+	}
+
+	if len(o.Exclude) > 0 {
+		opts = append(opts, generator.Exclude(o.Exclude...))
+	}
+
+	for _, typ := range o.Types {
+		typeNames = append(typeNames, typ.Name)
+
+		if typ.Accessors != nil {
+			opts = append(opts, generator.TypeAccessors(typ.Name, *typ.Accessors))
+		}
+	}
+
+	return typeNames, opts, nil
+}
+
+func runConfig(w io.Writer, path string, check bool) error {
+	c, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+
+	var outdated bool
+
+	for _, output := range c.Outputs {
+		typeNames, opts, err := output.options()
+		if err != nil {
+			return fmt.Errorf("%s: %w", output.Output, err)
+		}
+
+		file := output.Output
+
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(path), file)
+		}
+
+		platforms, err := parsePlatforms(strings.Join(output.Platforms, ","))
+		if err != nil {
+			return fmt.Errorf("%s: %w", output.Output, err)
+		}
+
+		goVersions, err := parseGoVersions(strings.Join(output.GoVersions, ","))
+		if err != nil {
+			return fmt.Errorf("%s: %w", output.Output, err)
+		}
+
+		if err := generateVersions(w, "", file, goVersions, platforms, check, typeNames, opts...); errors.Is(err, ErrOutdated) {
+			outdated = true
+		} else if err != nil {
+			return fmt.Errorf("%s: %w", output.Output, err)
+		}
+	}
+
+	if outdated {
+		return ErrOutdated
+	}
+
+	return nil
+}
+
+var (
+	ErrInvalidConfig   = errors.New("invalid config file")
+	ErrDuplicateOutput = errors.New("duplicate output file")
+	ErrNoTypeName      = errors.New("no type name specified")
+)
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"vimagination.zapto.org/unsafe/generator"
+)
+
+func TestLoadConfig(t *testing.T) {
+	yes, no := true, false
+
+	for n, test := range [...]struct {
+		input  string
+		config *config
+		err    error
+	}{
+		{
+			`{"outputs": [{"output": "a.go", "types": ["strings.Reader"]}]}`,
+			&config{Outputs: []outputConfig{{Output: "a.go", Types: []typeConfig{{Name: "strings.Reader"}}}}},
+			nil,
+		},
+		{
+			`{"outputs": [{"output": "a.go", "package": "a_test", "accessors": true, "tags": "json", "types": ["strings.Reader", {"name": "strings.Builder", "accessors": false}], "exclude": ["strings.Reader"]}, {"output": "b/b.go", "types": [{"name": "bytes.Buffer", "accessors": true}]}]}`,
+			&config{Outputs: []outputConfig{
+				{Output: "a.go", Package: "a_test", Accessors: true, Tags: "json", Types: []typeConfig{{Name: "strings.Reader"}, {Name: "strings.Builder", Accessors: &no}}, Exclude: []string{"strings.Reader"}},
+				{Output: "b/b.go", Types: []typeConfig{{Name: "bytes.Buffer", Accessors: &yes}}},
+			}},
+			nil,
+		},
+		{
+			`{"outputs": [{"types": ["strings.Reader"]}]}`,
+			nil,
+			ErrNoOutput,
+		},
+		{
+			`{"outputs": [{"output": "a.go", "types": ["strings.Reader"]}, {"output": "./a.go", "types": ["strings.Builder"]}]}`,
+			nil,
+			ErrDuplicateOutput,
+		},
+		{
+			`{"outputs": [{"output": "a.go", "types": [{"accessors": true}]}]}`,
+			nil,
+			ErrNoTypeName,
+		},
+		{
+			`{"outputs": [{"output": "a.go", "type": ["strings.Reader"]}]}`,
+			nil,
+			ErrInvalidConfig,
+		},
+		{
+			`{"outputs": [{"output": "a.go", "types": [{"name": "strings.Reader", "acessors": true}]}]}`,
+			nil,
+			ErrInvalidConfig,
+		},
+	} {
+		path := filepath.Join(t.TempDir(), "unsafe.json")
+
+		if err := os.WriteFile(path, []byte(test.input), 0o644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if c, err := loadConfig(path); !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if !reflect.DeepEqual(c, test.config) {
+			t.Errorf("test %d: expecting config %v, got %v", n+1, test.config, c)
+		}
+	}
+}
+
+func TestOutputConfigOptions(t *testing.T) {
+	dir := buildPackage(t)
+
+	yes := true
+
+	for n, test := range [...]struct {
+		output    outputConfig
+		typeNames []string
+		options   []generator.Option
+		err       error
+	}{
+		{
+			outputConfig{Types: []typeConfig{{Name: "strings.Reader"}}},
+			[]string{"strings.Reader"},
+			[]generator.Option{generator.PackageName(""), generator.TagRewrites(nil)},
+			nil,
+		},
+		{
+			outputConfig{Accessors: true, Types: []typeConfig{{Name: "strings.R*"}, {Name: "strings.Builder", Accessors: &yes}}, Exclude: []string{"strings.Replacer"}},
+			[]string{"strings.R*", "strings.Builder"},
+			[]generator.Option{generator.PackageName(""), generator.TagRewrites(nil), generator.Accessors(), generator.Exclude("strings.Replacer"), generator.TypeAccessors("strings.Builder", true)},
+			nil,
+		},
+		{
+			outputConfig{Package: "b", Tags: "-", Naming: "short", Types: []typeConfig{{Name: "strings.Reader"}}},
+			[]string{"strings.Reader"},
+			[]generator.Option{generator.PackageName("b"), generator.TagRewrites(map[string]string{}), generator.Naming(generator.ShortNaming)},
+			nil,
+		},
+		{
+			outputConfig{Tags: "a:b", Types: []typeConfig{{Name: "strings.Reader"}}},
+			nil,
+			nil,
+			ErrInvalidTagRewrite,
+		},
+	} {
+		typeNames, opts, err := test.output.options()
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if !reflect.DeepEqual(typeNames, test.typeNames) {
+			t.Errorf("test %d: expecting types %v, got %v", n+1, test.typeNames, typeNames)
+		} else if err == nil {
+			if expected, got := generateConfig(t, dir, typeNames, test.options), generateConfig(t, dir, typeNames, opts); expected != got {
+				t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, expected, got)
+			}
+		}
+	}
+}
+
+func generateConfig(t *testing.T, dir string, typeNames []string, opts []generator.Option) string {
+	t.Helper()
+
+	g, err := generator.New(dir, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	generated, err := g.Generate(typeNames...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return string(generated)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const diffContext = 3
+
+type edit struct {
+	op   byte
+	line string
+}
+
+func splitLines(data string) []string {
+	lines := strings.SplitAfter(data, "\n")
+
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+func diffLines(a, b []string) []edit {
+	return appendDiff(make([]edit, 0, len(a)+len(b)), a, b)
+}
+
+// appendDiff appends the edits that transform a into b, splitting the problem
+// at the middle snake of the Myers algorithm so that the space used is linear
+// in the length of the input.
+func appendDiff(edits []edit, a, b []string) []edit {
+	var prefix, suffix int
+
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, line := range a[:prefix] {
+		edits = append(edits, edit{' ', line})
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	switch {
+	case len(ma) == 0:
+		for _, line := range mb {
+			edits = append(edits, edit{'+', line})
+		}
+	case len(mb) == 0:
+		for _, line := range ma {
+			edits = append(edits, edit{'-', line})
+		}
+	default:
+		x, y, u, v := middleSnake(ma, mb)
+		edits = appendDiff(edits, ma[:x], mb[:y])
+
+		for _, line := range ma[x:u] {
+			edits = append(edits, edit{' ', line})
+		}
+
+		edits = appendDiff(edits, ma[u:], mb[v:])
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, edit{' ', line})
+	}
+
+	return edits
+}
+
+// middleSnake finds the snake, from (x, y) to (u, v), in the middle of a
+// shortest edit script between a and b, by running the Myers algorithm
+// forwards from the start and backwards from the end until the paths overlap.
+//
+// The first and last lines of a and b must differ.
+func middleSnake(a, b []string) (x, y, u, v int) {
+	var (
+		n, m   = len(a), len(b)
+		delta  = n - m
+		odd    = delta&1 != 0
+		limit  = (n + m + 1) / 2
+		offset = limit + 1
+		vf     = make([]int, 2*limit+3)
+		vb     = make([]int, 2*limit+3)
+	)
+
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			if k == -d || k != d && vf[offset+k-1] < vf[offset+k+1] {
+				x = vf[offset+k+1]
+			} else {
+				x = vf[offset+k-1] + 1
+			}
+
+			y = x - k
+			u, v = x, y
+
+			for u < n && v < m && a[u] == b[v] {
+				u++
+				v++
+			}
+
+			vf[offset+k] = u
+
+			if r := delta - k; odd && r >= -(d-1) && r <= d-1 && u+vb[offset+r] >= n {
+				return x, y, u, v
+			}
+		}
+
+		for k := -d; k <= d; k += 2 {
+			var rx int
+
+			if k == -d || k != d && vb[offset+k-1] < vb[offset+k+1] {
+				rx = vb[offset+k+1]
+			} else {
+				rx = vb[offset+k-1] + 1
+			}
+
+			ry := rx - k
+			ru, rv := rx, ry
+
+			for ru < n && rv < m && a[n-1-ru] == b[m-1-rv] {
+				ru++
+				rv++
+			}
+
+			vb[offset+k] = ru
+
+			if f := delta - k; !odd && f >= -d && f <= d && ru+vf[offset+f] >= n {
+				return n - ru, m - rv, n - rx, m - ry
+			}
+		}
+	}
+
+	return 0, 0, 0, 0
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	} else if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func unifiedDiff(w io.Writer, name, from, to string) error {
+	edits := diffLines(splitLines(from), splitLines(to))
+	aPos := make([]int, len(edits)+1)
+	bPos := make([]int, len(edits)+1)
+
+	for n, e := range edits {
+		aPos[n+1] = aPos[n]
+		bPos[n+1] = bPos[n]
+
+		if e.op != '+' {
+			aPos[n+1]++
+		}
+
+		if e.op != '-' {
+			bPos[n+1]++
+		}
+	}
+
+	header := false
+
+	for n := 0; n < len(edits); n++ {
+		if edits[n].op == ' ' {
+			continue
+		}
+
+		start := max(n-diffContext, 0)
+		end := n
+
+		for m := n; m < len(edits) && m <= end+2*diffContext; m++ {
+			if edits[m].op != ' ' {
+				end = m
+			}
+		}
+
+		end = min(end+diffContext+1, len(edits))
+
+		if !header {
+			if _, err := fmt.Fprintf(w, "--- %s\n+++ %s\n", name, name); err != nil {
+				return err
+			}
+
+			header = true
+		}
+
+		if _, err := fmt.Fprintf(w, "@@ -%s +%s @@\n", hunkRange(aPos[start], aPos[end]-aPos[start]), hunkRange(bPos[start], bPos[end]-bPos[start])); err != nil {
+			return err
+		}
+
+		for _, e := range edits[start:end] {
+			line := e.line
+
+			if !strings.HasSuffix(line, "\n") {
+				line += "\n\\ No newline at end of file\n"
+			}
+
+			if _, err := fmt.Fprintf(w, "%c%s", e.op, line); err != nil {
+				return err
+			}
+		}
+
+		n = end - 1
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	for n, test := range [...]struct {
+		from, to, output string
+	}{
+		{
+			"a\nb\nc\n",
+			"a\nb\nc\n",
+			"",
+		},
+		{
+			"",
+			"a\nb\n",
+			"--- file.go\n+++ file.go\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			"a\nb\nc\n",
+			"a\nd\nc\n",
+			"--- file.go\n+++ file.go\n@@ -1,3 +1,3 @@\n a\n-b\n+d\n c\n",
+		},
+		{
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n",
+			"1\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n15a\n16\n",
+			"--- file.go\n+++ file.go\n@@ -1,5 +1,4 @@\n 1\n-2\n 3\n 4\n 5\n@@ -13,4 +12,5 @@\n 13\n 14\n 15\n+15a\n 16\n",
+		},
+		{
+			"1\n2\n3\n4\n5\n6\n7\n8\n",
+			"1\n2\n3\n4\n6\n7\n8\n9\n",
+			"--- file.go\n+++ file.go\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n 6\n 7\n 8\n+9\n",
+		},
+		{
+			"a\nb",
+			"a\nb\n",
+			"--- file.go\n+++ file.go\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			"a\nb\nc\n",
+			"x\na\nc\ny",
+			"--- file.go\n+++ file.go\n@@ -1,3 +1,4 @@\n+x\n a\n-b\n c\n+y\n\\ No newline at end of file\n",
+		},
+	} {
+		var buf strings.Builder
+
+		if err := unifiedDiff(&buf, "file.go", test.from, test.to); err != nil {
+			t.Errorf("test %d: unexpected error: %s", n+1, err)
+		} else if str := buf.String(); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		}
+	}
+}
+
+func TestUnifiedDiffLarge(t *testing.T) {
+	var from, to strings.Builder
+
+	for n := range 200000 {
+		fmt.Fprintf(&from, "%d\n", n)
+
+		if n == 0 || n == 100000 {
+			fmt.Fprintf(&to, "%d changed\n", n)
+		} else {
+			fmt.Fprintf(&to, "%d\n", n)
+		}
+	}
+
+	var buf strings.Builder
+
+	if err := unifiedDiff(&buf, "file.go", from.String(), to.String()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if hunks := strings.Count(buf.String(), "@@ -"); hunks != 2 {
+		t.Errorf("expecting 2 hunks, got %d", hunks)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	for n := range 1000 {
+		a := make([]string, rng.IntN(12))
+		b := make([]string, rng.IntN(12))
+
+		for i := range a {
+			a[i] = string(rune('a' + rng.IntN(3)))
+		}
+
+		for i := range b {
+			b[i] = string(rune('a' + rng.IntN(3)))
+		}
+
+		var from, to []string
+
+		changes := 0
+
+		for _, e := range diffLines(a, b) {
+			if e.op != '+' {
+				from = append(from, e.line)
+			}
+
+			if e.op != '-' {
+				to = append(to, e.line)
+			}
+
+			if e.op != ' ' {
+				changes++
+			}
+		}
+
+		if strings.Join(from, "") != strings.Join(a, "") || strings.Join(to, "") != strings.Join(b, "") {
+			t.Errorf("test %d: edits do not transform %q into %q", n+1, a, b)
+		} else if expected := len(a) + len(b) - 2*lcsLength(a, b); changes != expected {
+			t.Errorf("test %d: expecting %d changes between %q and %q, got %d", n+1, expected, a, b, changes)
+		}
+	}
+}
+
+func lcsLength(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	return lcs[0][0]
+}
+
+func TestUnifiedDiffDisjoint(t *testing.T) {
+	var from, to strings.Builder
+
+	for n := range 4000 {
+		fmt.Fprintf(&from, "a%d\n", n)
+		fmt.Fprintf(&to, "b%d\n", n)
+	}
+
+	var (
+		buf           strings.Builder
+		before, after runtime.MemStats
+	)
+
+	runtime.ReadMemStats(&before)
+
+	err := unifiedDiff(&buf, "file.go", from.String(), to.String())
+
+	runtime.ReadMemStats(&after)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if hunks := strings.Count(buf.String(), "@@ -"); hunks != 1 {
+		t.Errorf("expecting 1 hunk, got %d", hunks)
+	} else if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 16<<20 {
+		t.Errorf("expecting less than 16MB to be allocated, got %d bytes", alloc)
+	}
+}
@@ -0,0 +1,263 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+func (b *builder) hasAccessors(name string) bool {
+	if enabled, ok := b.typeAccessors[name]; ok {
+		return enabled
+	}
+
+	return b.accessors
+}
+
+func (b *builder) addAccessors(decls []ast.Decl) []ast.Decl {
+	if len(b.methods) == 0 {
+		return decls
+	}
+
+	var ndecls []ast.Decl
+
+	for _, decl := range decls {
+		ndecls = append(ndecls, decl)
+
+		if gen, ok := decl.(*ast.GenDecl); ok {
+			ndecls = append(ndecls, b.methods[gen.Specs[0].(*ast.TypeSpec).Name.Name]...)
+		}
+	}
+
+	return ndecls
+}
+
+func buildAccessors(decl *ast.GenDecl, typ types.Type) ([]ast.Decl, error) {
+	spec := decl.Specs[0].(*ast.TypeSpec)
+
+	str, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, nil
+	}
+
+	names := accessorNames{
+		recv:  receiverType(spec),
+		x:     freeName("x", spec.TypeParams),
+		value: freeName("v", spec.TypeParams),
+	}
+	decls := names.fieldAccessors("", names.x, typ.Underlying().(*types.Struct), str)
+	seen := make(map[string]struct{}, len(decls)+len(str.Fields.List))
+
+	for _, field := range str.Fields.List {
+		seen[fieldName(field)] = struct{}{}
+	}
+
+	for _, decl := range decls {
+		name := decl.(*ast.FuncDecl).Name.Name
+
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("%w: %s.%s", ErrAccessorCollision, spec.Name.Name, name)
+		}
+
+		seen[name] = struct{}{}
+	}
+
+	return decls, nil
+}
+
+func (b *builder) checkAccessors() error {
+	for typeName, methods := range b.methods {
+		intf, ok := b.implements[typeName]
+		if !ok {
+			continue
+		}
+
+		for _, method := range methods {
+			name := method.(*ast.FuncDecl).Name.Name
+
+			for fn := range intf.Methods() {
+				if fn.Name() == name {
+					return fmt.Errorf("%w: %s.%s", ErrAccessorCollision, typeName, name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func freeName(name string, params *ast.FieldList) *ast.Ident {
+	if params == nil {
+		return ast.NewIdent(name)
+	}
+
+	used := make(map[string]struct{})
+
+	for _, param := range params.List {
+		for _, ident := range param.Names {
+			used[ident.Name] = struct{}{}
+		}
+	}
+
+	free := name
+
+	for n := 0; ; n++ {
+		if _, ok := used[free]; !ok {
+			return ast.NewIdent(free)
+		}
+
+		free = name + strconv.Itoa(n)
+	}
+}
+
+type accessorNames struct {
+	recv     ast.Expr
+	x, value *ast.Ident
+}
+
+func receiverType(typ *ast.TypeSpec) ast.Expr {
+	var recv ast.Expr = ast.NewIdent(typ.Name.Name)
+
+	if typ.TypeParams != nil {
+		var indicies []ast.Expr
+
+		for _, param := range typ.TypeParams.List {
+			for _, name := range param.Names {
+				indicies = append(indicies, ast.NewIdent(name.Name))
+			}
+		}
+
+		recv = &ast.IndexListExpr{
+			X:       recv,
+			Indices: indicies,
+		}
+	}
+
+	return &ast.StarExpr{X: recv}
+}
+
+func (a accessorNames) fieldAccessors(prefix string, sel ast.Expr, typ *types.Struct, str *ast.StructType) []ast.Decl {
+	var decls []ast.Decl
+
+	for n, field := range str.Fields.List {
+		v := typ.Field(n)
+
+		if v.Name() == "_" || containsLock(v.Type()) {
+			continue
+		}
+
+		name := prefix + exportName(v.Name())
+		fieldSel := &ast.SelectorExpr{
+			X:   sel,
+			Sel: ast.NewIdent(fieldName(field)),
+		}
+
+		decls = append(decls, a.getter(name, fieldSel, field.Type), a.setter(name, fieldSel, field.Type))
+
+		if nested, ok := field.Type.(*ast.StructType); ok {
+			decls = append(decls, a.fieldAccessors(name, fieldSel, v.Type().Underlying().(*types.Struct), nested)...)
+		}
+	}
+
+	return decls
+}
+
+func containsLock(typ types.Type) bool {
+	if _, ok := typ.Underlying().(*types.Interface); ok {
+		return false
+	}
+
+	if methods := types.NewMethodSet(types.NewPointer(typ)); methods.Lookup(nil, "Lock") != nil && methods.Lookup(nil, "Unlock") != nil {
+		return true
+	}
+
+	switch t := typ.Underlying().(type) {
+	case *types.Struct:
+		for field := range t.Fields() {
+			if containsLock(field.Type()) {
+				return true
+			}
+		}
+	case *types.Array:
+		return containsLock(t.Elem())
+	}
+
+	return false
+}
+
+func exportName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+
+	return string(unicode.ToUpper(r)) + name[size:]
+}
+
+func (a accessorNames) getter(name string, sel, typ ast.Expr) *ast.FuncDecl {
+	return &ast.FuncDecl{
+		Recv: &ast.FieldList{
+			List: []*ast.Field{
+				{
+					Names: []*ast.Ident{a.x},
+					Type:  a.recv,
+				},
+			},
+		},
+		Name: ast.NewIdent("Get" + name),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{},
+			Results: &ast.FieldList{
+				List: []*ast.Field{
+					{
+						Type: typ,
+					},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ReturnStmt{
+					Results: []ast.Expr{sel},
+				},
+			},
+		},
+	}
+}
+
+func (a accessorNames) setter(name string, sel, typ ast.Expr) *ast.FuncDecl {
+	return &ast.FuncDecl{
+		Recv: &ast.FieldList{
+			List: []*ast.Field{
+				{
+					Names: []*ast.Ident{a.x},
+					Type:  a.recv,
+				},
+			},
+		},
+		Name: ast.NewIdent("Set" + name),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: []*ast.Field{
+					{
+						Names: []*ast.Ident{a.value},
+						Type:  typ,
+					},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{sel},
+					Tok: token.ASSIGN,
+					Rhs: []ast.Expr{a.value},
+				},
+			},
+		},
+	}
+}
+
+var ErrAccessorCollision = errors.New("accessor name collision")
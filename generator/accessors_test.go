@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"errors"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/module"
+	"vimagination.zapto.org/gotypes"
+)
+
+func TestAddAccessors(t *testing.T) {
+	for n, test := range [...]struct {
+		input, output string
+	}{
+		{"package a\n\ntype a struct { b int }", "type a struct {\n\tb int\n}\n\nfunc (x *a) GetB() int {\n\treturn x.b\n}\n\nfunc (x *a) SetB(v int) {\n\tx.b = v\n}\n"},
+		{"package a\n\ntype a struct { _ int; B, c string }", "type a struct {\n\t_ int\n\tB string\n\tc string\n}\n\nfunc (x *a) GetB() string {\n\treturn x.B\n}\n\nfunc (x *a) SetB(v string) {\n\tx.B = v\n}\n\nfunc (x *a) GetC() string {\n\treturn x.c\n}\n\nfunc (x *a) SetC(v string) {\n\tx.c = v\n}\n"},
+		{"package a\n\ntype a struct { b struct { c bool } }", "type a struct {\n\tb struct {\n\t\tc bool\n\t}\n}\n\nfunc (x *a) GetB() struct {\n\tc bool\n} {\n\treturn x.b\n}\n\nfunc (x *a) SetB(v struct {\n\tc bool\n}) {\n\tx.b = v\n}\n\nfunc (x *a) GetBC() bool {\n\treturn x.b.c\n}\n\nfunc (x *a) SetBC(v bool) {\n\tx.b.c = v\n}\n"},
+		{"package a\n\nimport \"sync\"\n\ntype a struct { mu sync.Mutex; b [2]sync.Mutex; c *sync.Mutex; d struct { e sync.Mutex } }", "type a struct {\n\tmu sync.Mutex\n\tb  [2]sync.Mutex\n\tc  *sync.Mutex\n\td  struct {\n\t\te sync.Mutex\n\t}\n}\n\nfunc (x *a) GetC() *sync.Mutex {\n\treturn x.c\n}\n\nfunc (x *a) SetC(v *sync.Mutex) {\n\tx.c = v\n}\n"},
+		{"package a\n\ntype a struct { *b }\ntype b struct { c int }", "type a struct {\n\t*b\n}\n\nfunc (x *a) GetB() *b {\n\treturn x.b\n}\n\nfunc (x *a) SetB(v *b) {\n\tx.b = v\n}\n"},
+		{"package a\n\ntype a[T any] struct { b T }", "type a[T any] struct {\n\tb T\n}\n\nfunc (x *a[T]) GetB() T {\n\treturn x.b\n}\n\nfunc (x *a[T]) SetB(v T) {\n\tx.b = v\n}\n"},
+		{"package a\n\ntype a[x, v any] struct { b x; c v }", "type a[x any, v any] struct {\n\tb x\n\tc v\n}\n\nfunc (x0 *a[x, v]) GetB() x {\n\treturn x0.b\n}\n\nfunc (x0 *a[x, v]) SetB(v0 x) {\n\tx0.b = v0\n}\n\nfunc (x0 *a[x, v]) GetC() v {\n\treturn x0.c\n}\n\nfunc (x0 *a[x, v]) SetC(v0 v) {\n\tx0.c = v0\n}\n"},
+	} {
+		var (
+			buf strings.Builder
+			b   builder
+		)
+
+		self := parseType(t, test.input)
+
+		b.init()
+
+		b.mod = &gotypes.ModFile{Imports: map[string]module.Version{}}
+		b.accessors = true
+		decl := b.conStruct("a", self)
+		methods, err := buildAccessors(decl, self)
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		}
+
+		b.methods["a"] = methods
+
+		b.genImports()
+
+		for _, decl := range b.addAccessors([]ast.Decl{decl}) {
+			format.Node(&buf, token.NewFileSet(), decl)
+			buf.WriteString("\n\n")
+		}
+
+		if str := strings.TrimSuffix(buf.String(), "\n"); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		}
+	}
+}
+
+func TestAccessorCollision(t *testing.T) {
+	for n, input := range [...]string{
+		"package a\n\ntype a struct { b struct { c int }; bC int }",
+		"package a\n\ntype a struct { B int; b int }",
+		"package a\n\ntype a struct { b int; GetB int }",
+		"package a\n\ntype a struct { SetB int; b struct { c int } }",
+	} {
+		var b builder
+
+		self := parseType(t, input)
+
+		b.init()
+
+		b.mod = &gotypes.ModFile{Imports: map[string]module.Version{}}
+		b.accessors = true
+
+		if _, err := buildAccessors(b.conStruct("a", self), self); !errors.Is(err, ErrAccessorCollision) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, ErrAccessorCollision, err)
+		}
+	}
+}
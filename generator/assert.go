@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+func (b *builder) buildAssertions(decl *ast.GenDecl, typ types.Type) *ast.GenDecl {
+	namedType, ok := typ.(*types.Named)
+	if !ok || (namedType.TypeParams() != nil && !isConcrete(namedType)) || isOpaque(namedType) {
+		return nil
+	}
+
+	spec := decl.Specs[0].(*ast.TypeSpec)
+
+	var (
+		local = zeroValue(ast.NewIdent(spec.Name.Name), namedType)
+		orig  = zeroValue(b.originalType(namedType), namedType)
+		specs = []ast.Spec{
+			assertEqual("Sizeof", local, orig),
+			assertEqual("Alignof", local, orig),
+		}
+	)
+
+	if str, ok := b.layout(decl, namedType).Underlying().(*types.Struct); ok {
+		localFields := spec.Type.(*ast.StructType).Fields.List
+
+		for n := range str.NumFields() {
+			if field := str.Field(n); field.Exported() {
+				specs = append(specs, assertEqual("Offsetof", &ast.SelectorExpr{
+					X:   local,
+					Sel: ast.NewIdent(fieldName(localFields[n])),
+				}, &ast.SelectorExpr{
+					X:   orig,
+					Sel: ast.NewIdent(field.Name()),
+				}))
+			}
+		}
+	}
+
+	return &ast.GenDecl{
+		Tok:   token.CONST,
+		Specs: specs,
+	}
+}
+
+func zeroValue(name ast.Expr, typ types.Type) ast.Expr {
+	switch typ.Underlying().(type) {
+	case *types.Struct, *types.Array, *types.Slice, *types.Map:
+		return &ast.CompositeLit{Type: name}
+	}
+
+	return &ast.StarExpr{
+		X: &ast.CallExpr{
+			Fun:  ast.NewIdent("new"),
+			Args: []ast.Expr{name},
+		},
+	}
+}
+
+func unsafeCall(fn string, arg ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   ast.NewIdent("unsafe"),
+			Sel: ast.NewIdent(fn),
+		},
+		Args: []ast.Expr{arg},
+	}
+}
+
+func assertEqual(fn string, local, orig ast.Expr) *ast.ValueSpec {
+	return &ast.ValueSpec{
+		Names: blankName,
+		Values: []ast.Expr{
+			&ast.UnaryExpr{
+				Op: token.SUB,
+				X: &ast.ParenExpr{
+					X: &ast.BinaryExpr{
+						X:  unsafeCall(fn, local),
+						Op: token.XOR,
+						Y:  unsafeCall(fn, orig),
+					},
+				},
+			},
+		},
+	}
+}
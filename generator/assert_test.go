@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"go/format"
+	"go/token"
+	"strings"
+	"testing"
+
+	"vimagination.zapto.org/gotypes"
+)
+
+func TestBuildAssertions(t *testing.T) {
+	b, err := newBuilder(".")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	imps := gotypes.Imports(b.pkg)
+	b.init()
+
+	for n, test := range [...]struct {
+		typ, res string
+	}{
+		{"strings.Reader", "const (\n\t_ = -(unsafe.Sizeof(strings_Reader{}) ^ unsafe.Sizeof(strings.Reader{}))\n\t_ = -(unsafe.Alignof(strings_Reader{}) ^ unsafe.Alignof(strings.Reader{}))\n)"},
+		{"go/ast.Ident", "const (\n\t_ = -(unsafe.Sizeof(go_ast_Ident{}) ^ unsafe.Sizeof(ast.Ident{}))\n\t_ = -(unsafe.Alignof(go_ast_Ident{}) ^ unsafe.Alignof(ast.Ident{}))\n\t_ = -(unsafe.Offsetof(go_ast_Ident{}.NamePos) ^ unsafe.Offsetof(ast.Ident{}.NamePos))\n\t_ = -(unsafe.Offsetof(go_ast_Ident{}.Name) ^ unsafe.Offsetof(ast.Ident{}.Name))\n\t_ = -(unsafe.Offsetof(go_ast_Ident{}.Obj) ^ unsafe.Offsetof(ast.Ident{}.Obj))\n)"},
+		{"go/token.Pos", "const (\n\t_ = -(unsafe.Sizeof(*new(go_token_Pos)) ^ unsafe.Sizeof(*new(token.Pos)))\n\t_ = -(unsafe.Alignof(*new(go_token_Pos)) ^ unsafe.Alignof(*new(token.Pos)))\n)"},
+		{"go/ast.CommentMap", "const (\n\t_ = -(unsafe.Sizeof(go_ast_CommentMap{}) ^ unsafe.Sizeof(ast.CommentMap{}))\n\t_ = -(unsafe.Alignof(go_ast_CommentMap{}) ^ unsafe.Alignof(ast.CommentMap{}))\n)"},
+		{"vimagination.zapto.org/httpreaderat.block", ""},
+		{"vimagination.zapto.org/cache.LRU", ""},
+	} {
+		var buf strings.Builder
+
+		str, err := b.getStruct(imps, test.typ)
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %s", n+1, err)
+		} else if decl := b.buildAssertions(b.conStruct(test.typ, str), str); decl == nil {
+			if test.res != "" {
+				t.Errorf("test %d: expecting assertions, got none", n+1)
+			}
+		} else {
+			b.genImports()
+
+			format.Node(&buf, token.NewFileSet(), decl)
+
+			if str := buf.String(); str != test.res {
+				t.Errorf("test %d: expecting assertions %q, got %q", n+1, test.res, str)
+			}
+		}
+	}
+}
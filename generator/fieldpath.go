@@ -0,0 +1,146 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+)
+
+func (b *builder) buildFieldPath(imps map[string]*types.Package, name string) (*ast.FuncDecl, error) {
+	typename, path, _ := strings.Cut(name, ":")
+
+	fields := strings.Split(path, ".")
+	if path == "" || fields[0] == "_" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFieldPath, name)
+	}
+
+	typ, err := b.getStruct(imps, typename)
+	if err != nil {
+		return nil, err
+	}
+
+	namedType, ok := typ.(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotType, typename)
+	} else if namedType.TypeParams() != nil && !isConcrete(namedType) {
+		return nil, fmt.Errorf("%w: %s", ErrGenericFieldPath, name)
+	}
+
+	root, target, err := b.fieldPath(namedType.Underlying(), fields)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, name)
+	}
+
+	var (
+		from = conversionType{unsafePointer, types.Typ[types.UnsafePointer]}
+		to   = conversionType{&ast.StarExpr{X: root}, types.NewPointer(namedType)}
+	)
+
+	if !isOpaque(namedType) {
+		from = conversionType{&ast.StarExpr{X: b.originalType(namedType)}, types.NewPointer(namedType)}
+	}
+
+	sel := conversionExpr(from, to)
+
+	for _, field := range fields {
+		sel = &ast.SelectorExpr{
+			X:   sel,
+			Sel: ast.NewIdent(field),
+		}
+	}
+
+	return &ast.FuncDecl{
+		Name: ast.NewIdent(b.typeName(name)),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: []*ast.Field{
+					{
+						Names: x,
+						Type:  from.expr,
+					},
+				},
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{
+					{
+						Type: &ast.StarExpr{X: target},
+					},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ReturnStmt{
+					Results: []ast.Expr{
+						&ast.UnaryExpr{
+							Op: token.AND,
+							X:  sel,
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (b *builder) fieldPath(typ types.Type, path []string) (ast.Expr, ast.Expr, error) {
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+		elem, target, err := b.fieldPath(ptr.Elem(), path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &ast.StarExpr{X: elem}, target, nil
+	}
+
+	str, ok := typ.Underlying().(*types.Struct)
+	if !ok {
+		return nil, nil, ErrInvalidFieldPath
+	}
+
+	vars := slices.Collect(str.Fields())
+	offsets := b.sizes.Offsetsof(vars)
+
+	for n, field := range vars {
+		if field.Name() != path[0] {
+			continue
+		}
+
+		var (
+			fieldType, target ast.Expr
+			err               error
+		)
+
+		if len(path) == 1 {
+			fieldType = b.fieldToType(field.Type())
+			target = fieldType
+		} else if path[1] == "_" {
+			return nil, nil, ErrInvalidFieldPath
+		} else if fieldType, target, err = b.fieldPath(field.Type(), path[1:]); err != nil {
+			return nil, nil, err
+		}
+
+		fields, _ := b.appendPadding(nil, nil, vars, offsets, 0, offsets[n])
+
+		return &ast.StructType{
+			Fields: &ast.FieldList{
+				List: append(fields, &ast.Field{
+					Names: []*ast.Ident{ast.NewIdent(path[0])},
+					Type:  fieldType,
+				}),
+			},
+		}, target, nil
+	}
+
+	return nil, nil, ErrNoField
+}
+
+var (
+	ErrInvalidFieldPath = errors.New("invalid field path")
+	ErrGenericFieldPath = errors.New("cannot access field path of generic type")
+	ErrNoField          = errors.New("no field found")
+)
@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"errors"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/module"
+	"vimagination.zapto.org/gotypes"
+)
+
+func TestBuildFieldPath(t *testing.T) {
+	pkg := parseFile(t, "package a\n\ntype a struct { b int; c *d; e string }\ntype d struct { f [2]int; g struct { h bool; i uint8 } }\ntype g[T any] struct { h int; i T }\ntype A a")
+	imps := map[string]*types.Package{"a": pkg}
+
+	for n, test := range [...]struct {
+		input, output string
+		err           error
+	}{
+		{input: "a.a:b", output: "func a_a_b(x unsafe.Pointer) *int {\n\treturn &(*struct {\n\t\tb int\n\t})(x).b\n}"},
+		{input: "a.a:e", output: "func a_a_e(x unsafe.Pointer) *string {\n\treturn &(*struct {\n\t\t_ [8]byte\n\t\t_ unsafe.Pointer\n\t\te string\n\t})(x).e\n}"},
+		{input: "a.a:c.g.i", output: "func a_a_c_g_i(x unsafe.Pointer) *uint8 {\n\treturn &(*struct {\n\t\t_ [8]byte\n\t\tc *struct {\n\t\t\t_ [16]byte\n\t\t\tg struct {\n\t\t\t\t_ [1]byte\n\t\t\t\ti uint8\n\t\t\t}\n\t\t}\n\t})(x).c.g.i\n}"},
+		{input: "a.g[string]:i", output: "func a_g_string_i(x unsafe.Pointer) *string {\n\treturn &(*struct {\n\t\t_ [8]byte\n\t\ti string\n\t})(x).i\n}"},
+		{input: "a.A:e", output: "func a_A_e(x *a.A) *string {\n\treturn &(*struct {\n\t\t_ [8]byte\n\t\t_ unsafe.Pointer\n\t\te string\n\t})(unsafe.Pointer(x)).e\n}"},
+		{input: "a.a:", err: ErrInvalidFieldPath},
+		{input: "a.a:_", err: ErrInvalidFieldPath},
+		{input: "a.a:b.c", err: ErrInvalidFieldPath},
+		{input: "a.a:f", err: ErrNoField},
+		{input: "a.a:c.g.j", err: ErrNoField},
+		{input: "a.g:i", err: ErrGenericFieldPath},
+	} {
+		var (
+			buf strings.Builder
+			b   builder
+		)
+
+		b.sizes = types.SizesFor("gc", "amd64")
+		b.init()
+		b.mod = &gotypes.ModFile{Imports: map[string]module.Version{}}
+
+		fn, err := b.buildFieldPath(imps, test.input)
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		decl := b.addNewLines([]ast.Decl{fn})
+
+		b.genImports()
+
+		fset := token.NewFileSet()
+
+		fset.AddFile("out.go", 1, len(b.pos)).SetLines(b.pos)
+		format.Node(&buf, fset, decl[0])
+
+		if str := buf.String(); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		}
+	}
+}
+
+func TestFieldPathDirective(t *testing.T) {
+	dir := buildPackage(t, module.Version{Path: "strings"}, "Reader")
+
+	for n, test := range [...]struct {
+		typeName, arch string
+	}{
+		{"strings.Reader:s", ""},
+		{"strings.Reader:i", build.Default.GOARCH},
+	} {
+		g, err := New(dir, GenerateComment("-o", "file.go", test.typeName))
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		}
+
+		out, err := g.GenerateOutput(test.typeName)
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		} else if out.PaddedArch != test.arch {
+			t.Errorf("test %d: expecting padded architecture %q, got %q", n+1, test.arch, out.PaddedArch)
+		} else if hasComment := strings.Contains(string(out.Source), generateComment); hasComment != (test.arch == "") {
+			t.Errorf("test %d: expecting go:generate comment to be %v, got:\n%s", n+1, test.arch == "", out.Source)
+		} else if directive, err := g.Directive(); err != nil {
+			t.Errorf("test %d: unexpected error: %s", n+1, err)
+		} else if !strings.HasPrefix(string(directive), generateComment) {
+			t.Errorf("test %d: expecting go:generate comment, got:\n%s", n+1, directive)
+		}
+	}
+}
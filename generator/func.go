@@ -0,0 +1,231 @@
+package generator
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+var (
+	x             = []*ast.Ident{ast.NewIdent("x")}
+	unsafePointer = &ast.SelectorExpr{
+		X:   ast.NewIdent("unsafe"),
+		Sel: ast.NewIdent("Pointer"),
+	}
+	conversion = []ast.Expr{
+		&ast.CallExpr{
+			Fun: unsafePointer,
+			Args: []ast.Expr{
+				ast.NewIdent("x"),
+			},
+		},
+	}
+)
+
+func (b *builder) buildFunc(typ types.Type) *ast.FuncDecl {
+	tname, otype, ntype, paramList := b.conversionTypes(typ)
+
+	return conversionFunc(b.declare(b.conversion("make", tname), "make "+tname), paramList, otype, ntype)
+}
+
+func (b *builder) buildUnmakeFunc(typ types.Type) *ast.FuncDecl {
+	tname, otype, ntype, paramList := b.conversionTypes(typ)
+
+	return conversionFunc(b.declare(b.conversion("unmake", tname), "unmake "+tname), paramList, ntype, otype)
+}
+
+// conversionType pairs a type expression with the type it is converted as,
+// so that conversions to and from unsafe.Pointer can be recognised.
+type conversionType struct {
+	expr ast.Expr
+	typ  types.Type
+}
+
+func (b *builder) conversionTypes(typ types.Type) (string, conversionType, conversionType, *ast.FieldList) {
+	namedType := typ.(*types.Named)
+	tname := b.typeName(namedTypeName(namedType))
+
+	var (
+		opaque             = isOpaque(namedType)
+		oname     ast.Expr = unsafePointer
+		nname     ast.Expr = ast.NewIdent(tname)
+		paramList *ast.FieldList
+	)
+
+	if !opaque {
+		oname = b.originalType(namedType)
+	}
+
+	if namedType.TypeParams() != nil && !isConcrete(namedType) {
+		paramList = new(ast.FieldList)
+		indicies := make([]ast.Expr, 0, namedType.TypeArgs().Len())
+
+		for param := range namedType.TypeParams().TypeParams() {
+			paramList.List = append(paramList.List, &ast.Field{
+				Names: []*ast.Ident{ast.NewIdent(param.Obj().Name())},
+				Type:  b.fieldToType(param.Constraint()),
+			})
+			indicies = append(indicies, b.fieldToType(param))
+		}
+
+		if !opaque {
+			oname = &ast.IndexListExpr{
+				X:       oname,
+				Indices: indicies,
+			}
+		}
+
+		nname = &ast.IndexListExpr{
+			X:       nname,
+			Indices: indicies,
+		}
+	}
+
+	if !opaque {
+		oname = &ast.StarExpr{X: oname}
+	}
+
+	otype := conversionType{oname, types.Typ[types.UnsafePointer]}
+
+	if !opaque {
+		otype.typ = types.NewPointer(namedType)
+	}
+
+	return tname, otype, conversionType{&ast.StarExpr{X: nname}, types.NewPointer(namedType)}, paramList
+}
+
+// isOpaque reports whether the original type cannot be referred to by the
+// generated code, as it, or one of its type arguments, is unexported or
+// declared in an internal package, and so must be converted as an
+// unsafe.Pointer.
+func isOpaque(typ types.Type) bool {
+	switch t := types.Unalias(typ).(type) {
+	case *types.Named:
+		if pkg := t.Obj().Pkg(); pkg != nil && (!t.Obj().Exported() || isInternal(pkg.Path())) {
+			return true
+		}
+
+		for arg := range t.TypeArgs().Types() {
+			if isOpaque(arg) {
+				return true
+			}
+		}
+	case *types.Pointer:
+		return isOpaque(t.Elem())
+	case *types.Slice:
+		return isOpaque(t.Elem())
+	case *types.Array:
+		return isOpaque(t.Elem())
+	case *types.Chan:
+		return isOpaque(t.Elem())
+	case *types.Map:
+		return isOpaque(t.Key()) || isOpaque(t.Elem())
+	}
+
+	return false
+}
+
+func conversionFunc(name string, paramList *ast.FieldList, from, to conversionType) *ast.FuncDecl {
+	return &ast.FuncDecl{
+		Name: ast.NewIdent(name),
+		Type: &ast.FuncType{
+			TypeParams: paramList,
+			Params: &ast.FieldList{
+				List: []*ast.Field{
+					{
+						Names: x,
+						Type:  from.expr,
+					},
+				},
+			},
+			Results: &ast.FieldList{
+				List: []*ast.Field{
+					{
+						Type: to.expr,
+					},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ReturnStmt{
+					Results: []ast.Expr{
+						conversionExpr(from, to),
+					},
+				},
+			},
+		},
+	}
+}
+
+func conversionExpr(from, to conversionType) ast.Expr {
+	if isUnsafePointer(to.typ) {
+		return conversion[0]
+	}
+
+	args := conversion
+
+	if isUnsafePointer(from.typ) {
+		args = []ast.Expr{x[0]}
+	}
+
+	return &ast.CallExpr{
+		Fun: &ast.ParenExpr{
+			X: to.expr,
+		},
+		Args: args,
+	}
+}
+
+func isUnsafePointer(typ types.Type) bool {
+	return types.Identical(typ, types.Typ[types.UnsafePointer])
+}
+
+var emptyReturn = &ast.BlockStmt{
+	List: []ast.Stmt{&ast.ReturnStmt{}},
+}
+
+func (b *builder) addRequiredMethods(decls []ast.Decl) []ast.Decl {
+	var ndecls []ast.Decl
+
+	for _, decl := range decls {
+		ndecls = append(ndecls, decl)
+		typ := decl.(*ast.GenDecl).Specs[0].(*ast.TypeSpec)
+		name := typ.Name.Name
+
+		if intf, ok := b.implements[name]; ok {
+			for method := range intf.Methods() {
+				ndecls = append(ndecls, &ast.FuncDecl{
+					Recv: &ast.FieldList{
+						List: []*ast.Field{
+							{
+								Type: b.handleNamed(intf.Type),
+							},
+						},
+					},
+					Name: ast.NewIdent(method.Name()),
+					Type: &ast.FuncType{
+						Params: &ast.FieldList{
+							List: setBlankNames(b.structFieldList(method.Signature().Params().Variables, method.Signature().Variadic())),
+						},
+						Results: &ast.FieldList{
+							List: setBlankNames(b.structFieldList(method.Signature().Results().Variables, false)),
+						},
+					},
+					Body: emptyReturn,
+				})
+			}
+		}
+	}
+
+	return ndecls
+}
+
+var blankName = []*ast.Ident{ast.NewIdent("_")}
+
+func setBlankNames(fields []*ast.Field) []*ast.Field {
+	for n := range fields {
+		fields[n].Names = blankName
+	}
+
+	return fields
+}
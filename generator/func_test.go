@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"go/format"
+	"go/token"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/module"
+	"vimagination.zapto.org/gotypes"
+)
+
+func TestBuildFunc(t *testing.T) {
+	b, err := newBuilder(".")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	imps := gotypes.Imports(b.pkg)
+	b.internal = true
+	b.init()
+
+	for n, test := range [...]struct {
+		typ, res string
+	}{
+		{"strings.Reader", "func make_strings_Reader(x *strings.Reader) *strings_Reader {\n\treturn (*strings_Reader)(unsafe.Pointer(x))\n}"},
+		{"vimagination.zapto.org/httpreaderat.block", "func make_vimagination_zapto_org_httpreaderat_block(x unsafe.Pointer) *vimagination_zapto_org_httpreaderat_block {\n\treturn (*vimagination_zapto_org_httpreaderat_block)(x)\n}"},
+		{"vimagination.zapto.org/cache.LRU", "func make_vimagination_zapto_org_cache_LRU[T comparable, U any](x *cache.LRU[T, U]) *vimagination_zapto_org_cache_LRU[T, U] {\n\treturn (*vimagination_zapto_org_cache_LRU[T, U])(unsafe.Pointer(x))\n}"},
+		{"internal/types/errors.Code", "func make_internal_types_errors_Code(x unsafe.Pointer) *internal_types_errors_Code {\n\treturn (*internal_types_errors_Code)(x)\n}"},
+	} {
+		var buf strings.Builder
+
+		str, err := b.getStruct(imps, test.typ)
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %s", n+1, err)
+		} else {
+			b.genImports()
+
+			format.Node(&buf, token.NewFileSet(), b.buildFunc(str))
+
+			if str := buf.String(); str != test.res {
+				t.Errorf("test %d: expecting type %q, got %q", n+1, test.res, str)
+			}
+		}
+	}
+}
+
+func TestBuildUnmakeFunc(t *testing.T) {
+	b, err := newBuilder(".")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	imps := gotypes.Imports(b.pkg)
+	b.internal = true
+	b.init()
+
+	for n, test := range [...]struct {
+		typ, res string
+	}{
+		{"strings.Reader", "func unmake_strings_Reader(x *strings_Reader) *strings.Reader {\n\treturn (*strings.Reader)(unsafe.Pointer(x))\n}"},
+		{"vimagination.zapto.org/httpreaderat.block", "func unmake_vimagination_zapto_org_httpreaderat_block(x *vimagination_zapto_org_httpreaderat_block) unsafe.Pointer {\n\treturn unsafe.Pointer(x)\n}"},
+		{"vimagination.zapto.org/cache.LRU", "func unmake_vimagination_zapto_org_cache_LRU[T comparable, U any](x *vimagination_zapto_org_cache_LRU[T, U]) *cache.LRU[T, U] {\n\treturn (*cache.LRU[T, U])(unsafe.Pointer(x))\n}"},
+		{"internal/types/errors.Code", "func unmake_internal_types_errors_Code(x *internal_types_errors_Code) unsafe.Pointer {\n\treturn unsafe.Pointer(x)\n}"},
+	} {
+		var buf strings.Builder
+
+		str, err := b.getStruct(imps, test.typ)
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %s", n+1, err)
+		} else {
+			b.genImports()
+
+			format.Node(&buf, token.NewFileSet(), b.buildUnmakeFunc(str))
+
+			if str := buf.String(); str != test.res {
+				t.Errorf("test %d: expecting type %q, got %q", n+1, test.res, str)
+			}
+		}
+	}
+}
+
+func TestUnexportedConversion(t *testing.T) {
+	dir := buildPackage(t, module.Version{Path: "strings"}, "Reader")
+
+	for n, test := range [...]struct {
+		typeName, output string
+	}{
+		{"strings.asciiSet", `package a
+
+` + autoGenerated + `
+
+import "unsafe"
+
+type strings_asciiSet [256]bool
+
+func make_strings_asciiSet(x unsafe.Pointer) *strings_asciiSet {
+	return (*strings_asciiSet)(x)
+}
+
+func unmake_strings_asciiSet(x *strings_asciiSet) unsafe.Pointer {
+	return unsafe.Pointer(x)
+}
+`},
+		{"strings.appendSliceWriter", `package a
+
+` + autoGenerated + `
+
+import "unsafe"
+
+type strings_appendSliceWriter []byte
+
+func make_strings_appendSliceWriter(x unsafe.Pointer) *strings_appendSliceWriter {
+	return (*strings_appendSliceWriter)(x)
+}
+
+func unmake_strings_appendSliceWriter(x *strings_appendSliceWriter) unsafe.Pointer {
+	return unsafe.Pointer(x)
+}
+`},
+	} {
+		g, err := New(dir)
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		}
+
+		output, err := g.Generate(test.typeName)
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %s", n+1, err)
+		} else if str := string(output); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		} else {
+			vetOutput(t, dir, output)
+		}
+	}
+}
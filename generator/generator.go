@@ -0,0 +1,272 @@
+// Package generator localises types from other packages, allowing access to
+// their unexported fields.
+package generator
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"io"
+	"strings"
+)
+
+// Generator generates localised copies of types from the packages imported
+// by a Go package.
+type Generator struct {
+	builder
+	packageName string
+}
+
+// Option is a function that configures a Generator.
+type Option func(*Generator)
+
+// PackageName sets the package name of the generated file. The default is the
+// name of the package in the given directory.
+func PackageName(name string) Option {
+	return func(g *Generator) {
+		g.packageName = name
+	}
+}
+
+// GenerateComment adds a go:generate comment to the generated file, running
+// the unsafe command with the given arguments.
+func GenerateComment(args ...string) Option {
+	return func(g *Generator) {
+		g.args = args
+	}
+}
+
+// ToolVersion sets the version of the unsafe command run by the go:generate
+// comment, replacing 'latest', allowing regeneration to be reproducible.
+func ToolVersion(version string) Option {
+	return func(g *Generator) {
+		g.command = "go run " + ToolPath + "@" + version
+	}
+}
+
+// GoTool sets the go:generate comment to run the unsafe command with 'go tool',
+// using the version set by a tool directive in the go.mod file.
+func GoTool() Option {
+	return func(g *Generator) {
+		g.command = "go tool " + ToolPath
+	}
+}
+
+// Exclude sets a list of type names, or patterns, that will not be generated,
+// allowing unwanted types to be removed from those matched by a pattern.
+func Exclude(patterns ...string) Option {
+	return func(g *Generator) {
+		g.exclude = patterns
+	}
+}
+
+// Header replaces the 'DO NOT EDIT' comment at the top of the generated file
+// with the given text, which may span multiple lines.
+func Header(text string) Option {
+	return func(g *Generator) {
+		lines := strings.Split(text, "\n")
+
+		for n, line := range lines {
+			if line == "" {
+				lines[n] = "//"
+			} else {
+				lines[n] = "// " + line
+			}
+		}
+
+		g.header = strings.Join(lines, "\n")
+	}
+}
+
+// Naming sets the function used to determine the local name of a type, or
+// linked variable or function, from its fully qualified name (e.g.
+// 'vimagination.zapto.org/cache.LRU'). The default replaces all '_' with
+// '__', and then all '.' and '/', and other non-identifier characters with
+// '_', escaping the punctuation of any type arguments with '_' and a digit.
+func Naming(fn func(string) string) Option {
+	return func(g *Generator) {
+		g.naming = fn
+	}
+}
+
+// ConversionNaming sets the function used to determine the names of the
+// functions that convert to and from a localised type, from a verb ('make' or
+// 'unmake') and the local name of the type. The default joins them with '_'
+// (e.g. 'make_strings_Reader').
+func ConversionNaming(fn func(verb, typeName string) string) Option {
+	return func(g *Generator) {
+		g.conversion = fn
+	}
+}
+
+// Rename sets the local name of a single type, or linked variable or function,
+// overriding the naming function. A type that is only localised because it is
+// embedded keeps its original name, so that the embedded field keeps its name,
+// unless that name is already taken, in which case this can be used to choose
+// another. It is an error, reported as ErrInvalidNaming, for any local name to
+// not be a valid identifier, or to be a predeclared identifier such as 'int',
+// and an error, reported as ErrNameCollision, for it to be the name of a
+// package imported by the generated file.
+func Rename(name, local string) Option {
+	return func(g *Generator) {
+		if g.renames == nil {
+			g.renames = make(map[string]string)
+		}
+
+		g.renames[name] = local
+	}
+}
+
+// Accessors enables the generation of getter and setter methods for every
+// field of every localised struct.
+func Accessors() Option {
+	return func(g *Generator) {
+		g.accessors = true
+	}
+}
+
+// TypeAccessors enables or disables the generation of getter and setter
+// methods for the named type, overriding the Accessors option.
+func TypeAccessors(typeName string, enabled bool) Option {
+	return func(g *Generator) {
+		if g.typeAccessors == nil {
+			g.typeAccessors = make(map[string]bool)
+		}
+
+		g.typeAccessors[typeName] = enabled
+	}
+}
+
+// Internal allows types from internal and vendored packages to be localised.
+// Such types are always fully localised, both when referenced and when
+// specified directly, in which case the conversion functions use
+// unsafe.Pointer in place of the inaccessible original type.
+func Internal() Option {
+	return func(g *Generator) {
+		g.internal = true
+	}
+}
+
+// TagRewrites controls the copying of struct field tags. A nil map keeps all
+// tags, otherwise only tags with keys in the map are kept, renamed to the
+// corresponding value.
+func TagRewrites(rewrites map[string]string) Option {
+	return func(g *Generator) {
+		g.tags = rewrites
+	}
+}
+
+// Platform sets the GOOS and GOARCH used to load the package and to compute
+// type layouts, and adds a matching go:build constraint to the generated file.
+func Platform(goos, goarch string) Option {
+	return func(g *Generator) {
+		g.goos = goos
+		g.goarch = goarch
+	}
+}
+
+// GoVersion sets the GOROOT used to load standard library packages, and adds
+// a go:build constraint to the generated file limiting it to Go versions from
+// the given version (e.g. 'go1.22') up to, but excluding, the until version.
+// The until version may be empty to not set an upper limit.
+func GoVersion(goroot, from, until string) Option {
+	return func(g *Generator) {
+		g.goroot = goroot
+		g.goVersion = from
+		g.goUntil = until
+	}
+}
+
+// New creates a Generator for the Go package in the given directory, which
+// may localise types from any package that it imports.
+func New(dir string, opts ...Option) (*Generator, error) {
+	g := new(Generator)
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	if err := g.load(dir); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// File generates the AST for a file containing the given types, variables,
+// functions and methods, along with the FileSet required to correctly print
+// it.
+func (g *Generator) File(typeNames ...string) (*token.FileSet, *ast.File, error) {
+	return g.file(g.packageName, typeNames...)
+}
+
+// Generate returns the formatted source of a file containing the given types,
+// variables, functions and methods.
+func (g *Generator) Generate(typeNames ...string) ([]byte, error) {
+	out, err := g.GenerateOutput(typeNames...)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Source, nil
+}
+
+// Output is a generated file, as returned by GenerateOutput.
+type Output struct {
+	// Source is the formatted source of the file.
+	Source []byte
+
+	// PaddedArch is the GOARCH that the file is constrained to because it
+	// contains padded structs, whose layouts are specific to that
+	// architecture, or an empty string if there were none or a Platform was
+	// set. As go generate would not see it on other architectures, such a
+	// file does not contain the go:generate comment, which should instead be
+	// written to a separate file with Directive.
+	PaddedArch string
+}
+
+// GenerateOutput generates a file containing the given types, variables,
+// functions and methods, returning its formatted source along with the
+// architecture it is specific to.
+func (g *Generator) GenerateOutput(typeNames ...string) (*Output, error) {
+	fset, file, err := g.File(typeNames...)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+
+	return &Output{Source: buf.Bytes(), PaddedArch: g.paddedArch()}, nil
+}
+
+// WriteType writes the formatted source of a file containing the given types,
+// variables, functions and methods to the given Writer.
+func (g *Generator) WriteType(w io.Writer, typeNames ...string) error {
+	fset, file, err := g.File(typeNames...)
+	if err != nil {
+		return err
+	}
+
+	return format.Node(w, fset, file)
+}
+
+// Directive returns the source of a file containing only the go:generate
+// comment set with GenerateComment. As go generate skips files excluded by
+// their build constraints, this allows the comment to be kept in a separate,
+// unconstrained, file when the generated files are platform or version
+// specific.
+func (g *Generator) Directive() ([]byte, error) {
+	return g.directive(g.packageName)
+}
+
+// Inspect returns the memory layout of the given struct type, listing every
+// field, including those of nested structs, with their offsets, sizes and
+// alignments.
+func (g *Generator) Inspect(typeName string) (*Layout, error) {
+	return g.inspect(typeName)
+}
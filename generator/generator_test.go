@@ -0,0 +1,346 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"go/format"
+	"go/version"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/module"
+)
+
+func TestGenerator(t *testing.T) {
+	dir := buildPackage(t, module.Version{Path: "strings"}, "Reader")
+
+	if _, err := New(dir, Platform("linux", "unknown")); !errors.Is(err, ErrUnknownPlatform) {
+		t.Errorf("expecting error %v, got %v", ErrUnknownPlatform, err)
+	}
+
+	out, err := exec.Command("go", "env", "GOROOT", "GOVERSION").Output()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	goroot, goVersion, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	goVersion = version.Lang(goVersion)
+
+	if _, err := New(dir, GoVersion(goroot, "go1.24", "go1.23")); !errors.Is(err, ErrInvalidGoVersion) {
+		t.Errorf("expecting error %v, got %v", ErrInvalidGoVersion, err)
+	}
+
+	for n, test := range [...]struct {
+		opts   []Option
+		output string
+	}{
+		{
+			[]Option{PackageName("e")},
+			`package e
+
+` + autoGenerated + `
+
+import (
+	"strings"
+	"unsafe"
+)
+
+type strings_Reader struct {
+	s        string
+	i        int64
+	prevRune int
+}
+
+func make_strings_Reader(x *strings.Reader) *strings_Reader {
+	return (*strings_Reader)(unsafe.Pointer(x))
+}
+
+func unmake_strings_Reader(x *strings_Reader) *strings.Reader {
+	return (*strings.Reader)(unsafe.Pointer(x))
+}
+
+const (
+	_ = -(unsafe.Sizeof(strings_Reader{}) ^ unsafe.Sizeof(strings.Reader{}))
+	_ = -(unsafe.Alignof(strings_Reader{}) ^ unsafe.Alignof(strings.Reader{}))
+)
+`,
+		},
+		{
+			[]Option{PackageName("f"), GenerateComment("-o", "file.go", "strings.Reader"), Header("Generated code.\n\nDO NOT EDIT."), Naming(func(name string) string { return "local" + name[strings.LastIndexByte(name, '.')+1:] })},
+			`//go:generate go run vimagination.zapto.org/unsafe@latest -o file.go strings.Reader
+
+package f
+
+// Generated code.
+//
+// DO NOT EDIT.
+
+import (
+	"strings"
+	"unsafe"
+)
+
+type localReader struct {
+	s        string
+	i        int64
+	prevRune int
+}
+
+func make_localReader(x *strings.Reader) *localReader {
+	return (*localReader)(unsafe.Pointer(x))
+}
+
+func unmake_localReader(x *localReader) *strings.Reader {
+	return (*strings.Reader)(unsafe.Pointer(x))
+}
+
+const (
+	_ = -(unsafe.Sizeof(localReader{}) ^ unsafe.Sizeof(strings.Reader{}))
+	_ = -(unsafe.Alignof(localReader{}) ^ unsafe.Alignof(strings.Reader{}))
+)
+`,
+		},
+		{
+			[]Option{PackageName("e"), Platform("linux", "386"), GenerateComment("-o", "file.go", "-platforms", "linux/386", "strings.Reader")},
+			`//go:build linux && 386
+
+//go:generate go run vimagination.zapto.org/unsafe@latest -o file.go -platforms linux/386 strings.Reader
+
+package e
+
+// THIS FILE IS GENERATED BY THE COMMAND AT THE TOP; DO NOT EDIT
+
+import (
+	"strings"
+	"unsafe"
+)
+
+type strings_Reader struct {
+	s        string
+	i        int64
+	prevRune int
+}
+
+func make_strings_Reader(x *strings.Reader) *strings_Reader {
+	return (*strings_Reader)(unsafe.Pointer(x))
+}
+
+func unmake_strings_Reader(x *strings_Reader) *strings.Reader {
+	return (*strings.Reader)(unsafe.Pointer(x))
+}
+
+const (
+	_ = -(unsafe.Sizeof(strings_Reader{}) ^ unsafe.Sizeof(strings.Reader{}))
+	_ = -(unsafe.Alignof(strings_Reader{}) ^ unsafe.Alignof(strings.Reader{}))
+)
+`,
+		},
+		{
+			[]Option{PackageName("e"), ToolVersion("v1.2.3"), GenerateComment("-o", "file.go", "strings.Reader")},
+			`//go:generate go run vimagination.zapto.org/unsafe@v1.2.3 -o file.go strings.Reader
+
+package e
+
+// THIS FILE IS GENERATED BY THE COMMAND AT THE TOP; DO NOT EDIT
+
+import (
+	"strings"
+	"unsafe"
+)
+
+type strings_Reader struct {
+	s        string
+	i        int64
+	prevRune int
+}
+
+func make_strings_Reader(x *strings.Reader) *strings_Reader {
+	return (*strings_Reader)(unsafe.Pointer(x))
+}
+
+func unmake_strings_Reader(x *strings_Reader) *strings.Reader {
+	return (*strings.Reader)(unsafe.Pointer(x))
+}
+
+const (
+	_ = -(unsafe.Sizeof(strings_Reader{}) ^ unsafe.Sizeof(strings.Reader{}))
+	_ = -(unsafe.Alignof(strings_Reader{}) ^ unsafe.Alignof(strings.Reader{}))
+)
+`,
+		},
+		{
+			[]Option{PackageName("e"), GoTool(), GenerateComment("-o", "file.go", "strings.Reader")},
+			`//go:generate go tool vimagination.zapto.org/unsafe -o file.go strings.Reader
+
+package e
+
+// THIS FILE IS GENERATED BY THE COMMAND AT THE TOP; DO NOT EDIT
+
+import (
+	"strings"
+	"unsafe"
+)
+
+type strings_Reader struct {
+	s        string
+	i        int64
+	prevRune int
+}
+
+func make_strings_Reader(x *strings.Reader) *strings_Reader {
+	return (*strings_Reader)(unsafe.Pointer(x))
+}
+
+func unmake_strings_Reader(x *strings_Reader) *strings.Reader {
+	return (*strings.Reader)(unsafe.Pointer(x))
+}
+
+const (
+	_ = -(unsafe.Sizeof(strings_Reader{}) ^ unsafe.Sizeof(strings.Reader{}))
+	_ = -(unsafe.Alignof(strings_Reader{}) ^ unsafe.Alignof(strings.Reader{}))
+)
+`,
+		},
+		{
+			[]Option{PackageName("e"), Platform("linux", "amd64"), GoVersion(goroot, goVersion, "go1.999")},
+			`//go:build linux && amd64 && ` + goVersion + ` && !go1.999
+
+package e
+
+` + autoGenerated + `
+
+import (
+	"strings"
+	"unsafe"
+)
+
+type strings_Reader struct {
+	s        string
+	i        int64
+	prevRune int
+}
+
+func make_strings_Reader(x *strings.Reader) *strings_Reader {
+	return (*strings_Reader)(unsafe.Pointer(x))
+}
+
+func unmake_strings_Reader(x *strings_Reader) *strings.Reader {
+	return (*strings.Reader)(unsafe.Pointer(x))
+}
+
+const (
+	_ = -(unsafe.Sizeof(strings_Reader{}) ^ unsafe.Sizeof(strings.Reader{}))
+	_ = -(unsafe.Alignof(strings_Reader{}) ^ unsafe.Alignof(strings.Reader{}))
+)
+`,
+		},
+	} {
+		g, err := New(dir, test.opts...)
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		}
+
+		for range 2 {
+			output, err := g.Generate("strings.Reader")
+			if err != nil {
+				t.Fatalf("test %d: unexpected error: %s", n+1, err)
+			} else if str := string(output); str != test.output {
+				t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+			}
+		}
+
+		var buf bytes.Buffer
+
+		fset, file, err := g.File("strings.Reader")
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		} else if err := format.Node(&buf, fset, file); err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		} else if str := buf.String(); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		}
+	}
+}
+
+func TestGenerateConstraint(t *testing.T) {
+	dir := buildModule(t, map[string]string{
+		"go.mod":     "module a\n\ngo 1.25.5\n",
+		"a.go":       "package a\n\nimport (\n\t_ \"a/dep\"\n\t_ \"cmp\"\n)\n",
+		"dep/dep.go": "package dep\n\nimport \"cmp\"\n\ntype Num interface{ ~int | ~float64 }\n\ntype ordered interface{ cmp.Ordered }\n\ntype Pair[T Num] struct{ a, b T }\n\ntype Max[T ordered] struct{ v T }\n",
+	})
+
+	g, err := New(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for n, test := range [...]struct {
+		input []string
+		err   error
+	}{
+		{[]string{"cmp.Ordered"}, ErrConstraint},
+		{[]string{"a/dep.Num"}, ErrConstraint},
+		{[]string{"a/dep.Pair", "a/dep.ordered"}, ErrConstraint},
+		{[]string{"a/dep.Pair", "a/dep.Max"}, nil},
+	} {
+		if output, err := g.Generate(test.input...); !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if err == nil {
+			vetOutput(t, dir, output)
+		}
+	}
+}
+
+func TestDirective(t *testing.T) {
+	dir := buildPackage(t, module.Version{Path: "strings"}, "Reader")
+
+	for n, test := range [...]struct {
+		opts   []Option
+		output string
+		err    error
+	}{
+		{nil, "", ErrNoGenerateComment},
+		{[]Option{Platform("linux", "386"), GenerateComment("-o", "file.go", "-platforms", "linux/386", "strings.Reader")}, "//go:generate go run vimagination.zapto.org/unsafe@latest -o file.go -platforms linux/386 strings.Reader\n\npackage a\n\n" + autoGeneratedCommand + "\n", nil},
+		{[]Option{PackageName("b"), GoTool(), GenerateComment("-o", "file.go", "strings.Reader")}, "//go:generate go tool vimagination.zapto.org/unsafe -o file.go strings.Reader\n\npackage b\n\n" + autoGeneratedCommand + "\n", nil},
+		{[]Option{GenerateComment("-o", "file.go", "-t", "json\n//go:generate rm\tx", "strings.Reader")}, "//go:generate go run vimagination.zapto.org/unsafe@latest -o file.go -t \"json\\n//go:generate rm\\tx\" strings.Reader\n\npackage a\n\n" + autoGeneratedCommand + "\n", nil},
+	} {
+		g, err := New(dir, test.opts...)
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		}
+
+		output, err := g.Directive()
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if str := string(output); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		} else if _, err := format.Source(output); err != nil {
+			t.Errorf("test %d: unexpected error: %s", n+1, err)
+		}
+	}
+}
+
+// vetOutput writes the generated code into the package directory and runs go
+// vet on the package, to check that the generated code compiles.
+func vetOutput(t *testing.T, dir string, output []byte) {
+	t.Helper()
+
+	file := filepath.Join(dir, "generated.go")
+
+	if err := os.WriteFile(file, output, 0600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	defer os.Remove(file)
+
+	cmd := exec.Command("go", "vet", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("go vet failed: %s\n%s", err, out)
+	}
+}
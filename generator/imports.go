@@ -1,4 +1,4 @@
-package main
+package generator
 
 import (
 	"cmp"
@@ -16,28 +16,53 @@ const (
 )
 
 func isInternal(path string) bool {
-	return strings.Contains(path, "/internal/") || strings.HasSuffix(path, "/internal") || strings.HasPrefix(path, "internal/")
+	return hasElem(path, "internal") || hasElem(path, "vendor")
+}
+
+func hasElem(path, elem string) bool {
+	return path == elem || strings.HasPrefix(path, elem+"/") || strings.HasSuffix(path, "/"+elem) || strings.Contains(path, "/"+elem+"/")
 }
 
 func (b *builder) genImports() *ast.GenDecl {
-	doc := &ast.CommentGroup{
-		List: []*ast.Comment{
-			{
-				Slash: b.newLine(),
-				Text:  autoGenerated,
-			},
-		},
+	header := autoGenerated
+
+	if b.header != "" {
+		header = b.header
+	} else if len(b.args) > 0 {
+		header = autoGeneratedCommand
+	}
+
+	doc := new(ast.CommentGroup)
+	slash := b.newLine()
+
+	for n, line := range strings.Split(header, "\n") {
+		if n > 0 {
+			b.newLine()
+		}
+
+		doc.List = append(doc.List, &ast.Comment{
+			Slash: slash + token.Pos(n),
+			Text:  line,
+		})
 	}
+
 	tokPos := b.newLine()
 	names := map[string]struct{}{}
+
+	for path, pkg := range b.linked {
+		if !has(b.imports, path) && !isInternal(path) {
+			b.imports[path] = &packageName{pkg, ast.NewIdent("_")}
+		}
+	}
+
+	if len(b.linknames) > 0 && len(b.functions) == 0 && len(b.assertions) == 0 && !b.unsafePointer {
+		b.imports["unsafe"].Ident.Name = "_"
+	}
+
 	specs := b.buildImports(names, false)
 	stdlib := len(specs)
 	specs = append(specs, b.buildImports(names, true)...)
 
-	if len(b.args) > 0 {
-		doc.List[0].Text = autoGeneratedCommand
-	}
-
 	if len(specs) > stdlib {
 		if specs[stdlib].(*ast.ImportSpec).Name != nil {
 			specs[stdlib].(*ast.ImportSpec).Name.NamePos = b.newLine()
@@ -59,6 +84,18 @@ func (b *builder) buildImports(names map[string]struct{}, ext bool) []ast.Spec {
 
 	for _, imp := range sortedValues(b.imports) {
 		if _, isExt := b.mod.Imports[imp.Path()]; isExt == ext {
+			if imp.Ident.Name == "_" {
+				imps[imp.Path()] = &ast.ImportSpec{
+					Name: imp.Ident,
+					Path: &ast.BasicLit{
+						Kind:  token.STRING,
+						Value: strconv.Quote(imp.Path()),
+					},
+				}
+
+				continue
+			}
+
 			oname := imp.Package.Name()
 			name := oname
 			pos := 0
@@ -1,9 +1,12 @@
-package main
+package generator
 
 import (
 	"go/ast"
 	"go/token"
+	"go/version"
 	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 
 	"vimagination.zapto.org/gotypes"
@@ -133,3 +136,28 @@ func TestGenImports(t *testing.T) {
 		}
 	}
 }
+
+func TestVendoredImports(t *testing.T) {
+	// The module uses the language version of the toolchain, so that fields
+	// added to net/http.Server since the minimum version can be referenced.
+	dir := buildModule(t, map[string]string{
+		"go.mod": "module a\n\ngo " + strings.TrimPrefix(version.Lang(runtime.Version()), "go"),
+		"a.go":   "package a\n\nimport \"net/http\"\n\ntype c = http.Server",
+	})
+
+	g, err := New(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	output, err := g.Generate("net/http.Server")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if strings.Contains(string(output), "vendor/") {
+		t.Errorf("expecting no vendored imports, got:\n%s", output)
+	}
+
+	vetOutput(t, dir, output)
+}
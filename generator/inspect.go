@@ -0,0 +1,140 @@
+package generator
+
+import (
+	"go/types"
+
+	"vimagination.zapto.org/gotypes"
+)
+
+// Layout describes the memory layout of a struct type.
+type Layout struct {
+	Type   string  `json:"type"`
+	Size   int64   `json:"size"`
+	Align  int64   `json:"align"`
+	Fields []Field `json:"fields"`
+}
+
+// Field describes a single field of a struct type, with the fields of nested
+// structs, which are stored inline, following the field itself.
+//
+// The fields of structs referenced by a field, through pointers, slices and
+// maps, also follow it, marked as indirect, with offsets from the start of the
+// referenced struct. Elements of arrays, slices and maps are indicated in the
+// path with '[]' (e.g. 'e[].v'), and the fields of each referenced type are
+// only listed once.
+type Field struct {
+	Path     string `json:"path"`
+	Type     string `json:"type"`
+	Package  string `json:"package,omitempty"`
+	Offset   int64  `json:"offset"`
+	Size     int64  `json:"size"`
+	Align    int64  `json:"align"`
+	Exported bool   `json:"exported"`
+	Indirect bool   `json:"indirect,omitempty"`
+}
+
+func (b *builder) inspect(typeName string) (*Layout, error) {
+	b.init()
+
+	typ, err := b.getStruct(gotypes.Imports(b.pkg), typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkFields(typeName, typ, nil); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]struct{}{types.TypeString(typ, nil): {}}
+
+	return &Layout{
+		Type:   types.TypeString(typ, nil),
+		Size:   b.sizes.Sizeof(typ),
+		Align:  b.sizes.Alignof(typ),
+		Fields: b.inspectFields(nil, "", 0, false, typ.Underlying().(*types.Struct), seen),
+	}, nil
+}
+
+func (b *builder) inspectFields(fields []Field, prefix string, offset int64, indirect bool, str *types.Struct, seen map[string]struct{}) []Field {
+	vars := make([]*types.Var, 0, str.NumFields())
+
+	for field := range str.Fields() {
+		vars = append(vars, field)
+	}
+
+	for n, offsetof := range b.sizes.Offsetsof(vars) {
+		field := vars[n]
+		typ := field.Type()
+
+		fields = append(fields, Field{
+			Path:     prefix + field.Name(),
+			Type:     types.TypeString(typ, nil),
+			Package:  declaringPackage(typ),
+			Offset:   offset + offsetof,
+			Size:     b.sizes.Sizeof(typ),
+			Align:    b.sizes.Alignof(typ),
+			Exported: field.Exported(),
+			Indirect: indirect,
+		})
+
+		fields = b.inspectType(fields, prefix+field.Name(), offset+offsetof, indirect, typ, seen)
+	}
+
+	return fields
+}
+
+// inspectType appends the fields of any structs contained in, or referenced
+// by, a value of the given type.
+func (b *builder) inspectType(fields []Field, path string, offset int64, indirect bool, typ types.Type, seen map[string]struct{}) []Field {
+	switch t := typ.Underlying().(type) {
+	case *types.Struct:
+		return b.inspectFields(fields, path+".", offset, indirect, t, seen)
+	case *types.Array:
+		return b.inspectType(fields, path+"[]", offset, indirect, t.Elem(), seen)
+	case *types.Pointer:
+		return b.inspectReferenced(fields, path, t.Elem(), seen)
+	case *types.Slice:
+		return b.inspectReferenced(fields, path+"[]", t.Elem(), seen)
+	case *types.Map:
+		return b.inspectReferenced(fields, path+"[]", t.Elem(), seen)
+	}
+
+	return fields
+}
+
+func (b *builder) inspectReferenced(fields []Field, path string, typ types.Type, seen map[string]struct{}) []Field {
+	name := types.TypeString(typ, nil)
+
+	if _, ok := seen[name]; ok {
+		return fields
+	}
+
+	seen[name] = struct{}{}
+
+	return b.inspectType(fields, path, 0, true, typ, seen)
+}
+
+func declaringPackage(typ types.Type) string {
+	for {
+		switch t := types.Unalias(typ).(type) {
+		case *types.Named:
+			if pkg := t.Obj().Pkg(); pkg != nil {
+				return pkg.Path()
+			}
+
+			return ""
+		case *types.Pointer:
+			typ = t.Elem()
+		case *types.Slice:
+			typ = t.Elem()
+		case *types.Array:
+			typ = t.Elem()
+		case *types.Chan:
+			typ = t.Elem()
+		case *types.Map:
+			typ = t.Elem()
+		default:
+			return ""
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	dir := buildModule(t, map[string]string{
+		"go.mod": "module a\n\ngo 1.25.5\n",
+		"a.go":   "package a\n\nimport (\n\t_ \"container/list\"\n\t_ \"image\"\n)\n",
+	})
+
+	g, err := New(dir, Platform("linux", "amd64"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for n, test := range [...]struct {
+		typeName string
+		layout   *Layout
+		err      error
+	}{
+		{
+			typeName: "image.Rectangle",
+			layout: &Layout{
+				Type:  "image.Rectangle",
+				Size:  32,
+				Align: 8,
+				Fields: []Field{
+					{Path: "Min", Type: "image.Point", Package: "image", Offset: 0, Size: 16, Align: 8, Exported: true},
+					{Path: "Min.X", Type: "int", Offset: 0, Size: 8, Align: 8, Exported: true},
+					{Path: "Min.Y", Type: "int", Offset: 8, Size: 8, Align: 8, Exported: true},
+					{Path: "Max", Type: "image.Point", Package: "image", Offset: 16, Size: 16, Align: 8, Exported: true},
+					{Path: "Max.X", Type: "int", Offset: 16, Size: 8, Align: 8, Exported: true},
+					{Path: "Max.Y", Type: "int", Offset: 24, Size: 8, Align: 8, Exported: true},
+				},
+			},
+		},
+		{
+			typeName: "image.Uniform",
+			layout: &Layout{
+				Type:  "image.Uniform",
+				Size:  16,
+				Align: 8,
+				Fields: []Field{
+					{Path: "C", Type: "image/color.Color", Package: "image/color", Offset: 0, Size: 16, Align: 8, Exported: true},
+				},
+			},
+		},
+		{
+			typeName: "container/list.List",
+			layout: &Layout{
+				Type:  "container/list.List",
+				Size:  48,
+				Align: 8,
+				Fields: []Field{
+					{Path: "root", Type: "container/list.Element", Package: "container/list", Offset: 0, Size: 40, Align: 8},
+					{Path: "root.next", Type: "*container/list.Element", Package: "container/list", Offset: 0, Size: 8, Align: 8},
+					{Path: "root.next.next", Type: "*container/list.Element", Package: "container/list", Offset: 0, Size: 8, Align: 8, Indirect: true},
+					{Path: "root.next.prev", Type: "*container/list.Element", Package: "container/list", Offset: 8, Size: 8, Align: 8, Indirect: true},
+					{Path: "root.next.list", Type: "*container/list.List", Package: "container/list", Offset: 16, Size: 8, Align: 8, Indirect: true},
+					{Path: "root.next.Value", Type: "any", Offset: 24, Size: 16, Align: 8, Exported: true, Indirect: true},
+					{Path: "root.prev", Type: "*container/list.Element", Package: "container/list", Offset: 8, Size: 8, Align: 8},
+					{Path: "root.list", Type: "*container/list.List", Package: "container/list", Offset: 16, Size: 8, Align: 8},
+					{Path: "root.Value", Type: "any", Offset: 24, Size: 16, Align: 8, Exported: true},
+					{Path: "len", Type: "int", Offset: 40, Size: 8, Align: 8},
+				},
+			},
+		},
+		{
+			typeName: "image.YCbCrSubsampleRatio",
+			err:      ErrNotStruct,
+		},
+		{
+			typeName: "image.Unknown",
+			err:      ErrNoType,
+		},
+	} {
+		if layout, err := g.Inspect(test.typeName); !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if !reflect.DeepEqual(layout, test.layout) {
+			t.Errorf("test %d: expecting layout %v, got %v", n+1, test.layout, layout)
+		}
+	}
+}
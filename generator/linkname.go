@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+const linkname = "//go:linkname "
+
+func linknameDoc(local, symbol string) *ast.CommentGroup {
+	return &ast.CommentGroup{
+		List: []*ast.Comment{
+			{
+				Text: linkname + local + " " + symbol,
+			},
+		},
+	}
+}
+
+func (b *builder) buildVar(name string, v *types.Var) *ast.GenDecl {
+	b.linked[v.Pkg().Path()] = v.Pkg()
+
+	local := b.typeName(name)
+
+	return &ast.GenDecl{
+		Doc: linknameDoc(local, name),
+		Tok: token.VAR,
+		Specs: []ast.Spec{
+			&ast.ValueSpec{
+				Names: []*ast.Ident{ast.NewIdent(local)},
+				Type:  b.fieldToType(v.Type()),
+			},
+		},
+	}
+}
+
+func (b *builder) buildFunction(name string, fn *types.Func) (*ast.FuncDecl, error) {
+	if fn.Signature().TypeParams() != nil {
+		return nil, fmt.Errorf("%w: %s", ErrGenericFunc, name)
+	}
+
+	b.linked[fn.Pkg().Path()] = fn.Pkg()
+
+	return b.linkFunc(b.typeName(name), name, nil, fn.Signature()), nil
+}
+
+func (b *builder) buildMethod(imps map[string]*types.Package, name string) (*ast.FuncDecl, error) {
+	end := strings.Index(name, ").")
+	if end < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMethod, name)
+	}
+
+	typename := strings.TrimPrefix(name[1:end], "*")
+	method := name[end+2:]
+
+	obj, err := b.lookupObject(imps, typename)
+	if err != nil {
+		return nil, err
+	}
+
+	namedType, ok := obj.Type().(*types.Named)
+	if _, isType := obj.(*types.TypeName); !isType || !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoType, typename)
+	} else if namedType.TypeParams() != nil {
+		return nil, fmt.Errorf("%w: %s", ErrGenericFunc, name)
+	}
+
+	for fn := range namedType.Methods() {
+		if fn.Name() != method {
+			continue
+		}
+
+		var (
+			recv   ast.Expr = b.requiredTypeName(namedType)
+			symbol          = obj.Pkg().Path() + "." + obj.Name() + "." + method
+		)
+
+		if _, isPtr := fn.Signature().Recv().Type().(*types.Pointer); isPtr {
+			recv = &ast.StarExpr{X: recv}
+			symbol = obj.Pkg().Path() + ".(*" + obj.Name() + ")." + method
+		}
+
+		recvName := fn.Signature().Recv().Name()
+		if recvName == "" {
+			recvName = "_"
+		}
+
+		b.linked[obj.Pkg().Path()] = obj.Pkg()
+
+		return b.linkFunc(b.typeName(typename+"."+method), symbol, &ast.Field{
+			Names: []*ast.Ident{ast.NewIdent(recvName)},
+			Type:  recv,
+		}, fn.Signature()), nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNoMethod, name)
+}
+
+func (b *builder) linkFunc(local, symbol string, recv *ast.Field, sig *types.Signature) *ast.FuncDecl {
+	params := b.structFieldList(sig.Params().Variables, sig.Variadic())
+
+	if recv != nil {
+		if len(params) > 0 && params[0].Names == nil {
+			recv.Names = nil
+		}
+
+		params = append([]*ast.Field{recv}, params...)
+	}
+
+	return &ast.FuncDecl{
+		Doc:  linknameDoc(local, symbol),
+		Name: ast.NewIdent(local),
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: params,
+			},
+			Results: &ast.FieldList{
+				List: b.structFieldList(sig.Results().Variables, false),
+			},
+		},
+	}
+}
+
+var (
+	ErrGenericFunc   = errors.New("cannot link to generic function")
+	ErrInvalidMethod = errors.New("invalid method expression")
+	ErrNoMethod      = errors.New("no method found")
+)
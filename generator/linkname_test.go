@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"errors"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/module"
+	"vimagination.zapto.org/gotypes"
+)
+
+func TestBuildVar(t *testing.T) {
+	for n, test := range [...]struct {
+		input, output string
+	}{
+		{"package a\n\nvar b int", "//go:linkname a_b a.b\nvar a_b int"},
+		{"package a\n\nimport \"strings\"\n\nvar b *strings.Reader", "//go:linkname a_b a.b\nvar a_b *strings.Reader"},
+		{"package a\n\nvar b map[string]c\n\ntype c struct { d int }", "//go:linkname a_b a.b\nvar a_b map[string]struct {\n\td int\n}"},
+		{"package a\n\nvar b []*c\n\ntype c struct { d *c }", "//go:linkname a_b a.b\nvar a_b []*a_c"},
+	} {
+		var (
+			buf strings.Builder
+			b   builder
+		)
+
+		pkg := parseFile(t, test.input)
+
+		b.init()
+		b.mod = &gotypes.ModFile{Imports: map[string]module.Version{}}
+
+		decl := b.addNewLines([]ast.Decl{b.buildVar("a.b", pkg.Scope().Lookup("b").(*types.Var))})
+
+		b.genImports()
+
+		fset := token.NewFileSet()
+
+		fset.AddFile("out.go", 1, len(b.pos)).SetLines(b.pos)
+		format.Node(&buf, fset, decl[0])
+
+		if str := buf.String(); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		}
+	}
+}
+
+func TestBuildFunction(t *testing.T) {
+	for n, test := range [...]struct {
+		input, output string
+	}{
+		{"package a\n\nfunc b() {}", "//go:linkname a_b a.b\nfunc a_b()"},
+		{"package a\n\nfunc b(c int, d ...string) (e bool) { return }", "//go:linkname a_b a.b\nfunc a_b(c int, d ...string) (e bool)"},
+		{"package a\n\nfunc b(*c) error { return nil }\n\ntype c struct { d *c }", "//go:linkname a_b a.b\nfunc a_b(*a_c) error"},
+		{"package a\n\nfunc b[T any](T) {}", ""},
+	} {
+		var (
+			buf strings.Builder
+			b   builder
+		)
+
+		pkg := parseFile(t, test.input)
+
+		b.init()
+		b.mod = &gotypes.ModFile{Imports: map[string]module.Version{}}
+
+		fn, err := b.buildFunction("a.b", pkg.Scope().Lookup("b").(*types.Func))
+		if test.output == "" {
+			if !errors.Is(err, ErrGenericFunc) {
+				t.Errorf("test %d: expecting error %v, got %v", n+1, ErrGenericFunc, err)
+			}
+
+			continue
+		} else if err != nil {
+			t.Errorf("test %d: unexpected error: %s", n+1, err)
+
+			continue
+		}
+
+		decl := b.addNewLines([]ast.Decl{fn})
+
+		b.genImports()
+
+		fset := token.NewFileSet()
+
+		fset.AddFile("out.go", 1, len(b.pos)).SetLines(b.pos)
+		format.Node(&buf, fset, decl[0])
+
+		if str := buf.String(); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		}
+	}
+}
+
+func TestBuildMethod(t *testing.T) {
+	for n, test := range [...]struct {
+		input, method, output string
+		err                   error
+	}{
+		{"package a\n\ntype b struct { c int }\n\nfunc (d *b) e(f int) bool { return false }", "(*a.b).e", "//go:linkname a_b_e a.(*b).e\nfunc a_b_e(d *a_b, f int) bool", nil},
+		{"package a\n\ntype b struct { c int }\n\nfunc (b) e(int) {}", "(a.b).e", "//go:linkname a_b_e a.b.e\nfunc a_b_e(a_b, int)", nil},
+		{"package a\n\ntype b struct { c int }\n\nfunc (b) e(f int) {}", "(a.b).e", "//go:linkname a_b_e a.b.e\nfunc a_b_e(_ a_b, f int)", nil},
+		{"package a\n\ntype b struct { c int }", "(a.b).e", "", ErrNoMethod},
+		{"package a\n\ntype b struct { c int }", "(a.b.e", "", ErrInvalidMethod},
+		{"package a\n\ntype b[T any] struct { c T }\n\nfunc (b[T]) e() {}", "(a.b).e", "", ErrGenericFunc},
+	} {
+		var (
+			buf strings.Builder
+			b   builder
+		)
+
+		pkg := parseFile(t, test.input)
+
+		b.init()
+		b.mod = &gotypes.ModFile{Imports: map[string]module.Version{}}
+
+		fn, err := b.buildMethod(map[string]*types.Package{"a": pkg}, test.method)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+			}
+
+			continue
+		} else if err != nil {
+			t.Errorf("test %d: unexpected error: %s", n+1, err)
+
+			continue
+		}
+
+		decl := b.addNewLines([]ast.Decl{fn})
+
+		b.genImports()
+
+		fset := token.NewFileSet()
+
+		fset.AddFile("out.go", 1, len(b.pos)).SetLines(b.pos)
+		format.Node(&buf, fset, decl[0])
+
+		if str := buf.String(); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		} else if len(b.required) != 1 || b.required[0].name != "a.b" {
+			t.Errorf("test %d: expecting receiver type to be required", n+1)
+		}
+	}
+}
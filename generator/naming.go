@@ -0,0 +1,192 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Name contains the parts of a fully qualified name, as passed to a naming
+// function, for use in naming templates.
+type Name struct {
+	// Path is the import path of the package, e.g.
+	// 'vimagination.zapto.org/cache'.
+	Path string
+
+	// Pkg is the probable package name, taken from the last element of the
+	// path, e.g. 'cache'.
+	Pkg string
+
+	// Type is the name of the type, variable or function, along with any
+	// type arguments, method name or field path, converted to a valid
+	// identifier, e.g. 'LRU'.
+	Type string
+}
+
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+func splitName(name string) Name {
+	base, rest := name, ""
+
+	if pos := strings.IndexAny(name, "[:"); pos >= 0 {
+		base, rest = name[:pos], name[pos:]
+	}
+
+	start := strings.LastIndexByte(base, '/') + 1
+	pos := strings.IndexByte(base[start:], '.')
+
+	if pos < 0 {
+		return Name{Type: strings.Map(identRune, name)}
+	}
+
+	pos += start
+
+	if next := strings.IndexByte(base[pos+1:], '.'); next >= 0 && versionSuffix.MatchString(base[pos+1:pos+1+next]) {
+		pos += next + 1
+	}
+
+	path := base[:pos]
+	pkg := path[start:]
+
+	if dot := strings.IndexByte(pkg, '.'); dot >= 0 {
+		pkg = pkg[:dot]
+	} else if versionSuffix.MatchString(pkg) && start > 0 {
+		pkg = path[strings.LastIndexByte(path[:start-1], '/')+1 : start-1]
+	}
+
+	typ, args, rest := cutTypeArgs(base[pos+1:] + rest)
+
+	return Name{
+		Path: path,
+		Pkg:  strings.Map(identRune, pkg),
+		Type: strings.Trim(strings.Map(identRune, typ)+typeArgsName(args)+strings.Map(identRune, rest), "_"),
+	}
+}
+
+// ShortNaming is a naming function that prefixes the name of a type with its
+// package name, instead of its full path (e.g. 'cache_LRU' for
+// 'vimagination.zapto.org/cache.LRU').
+func ShortNaming(name string) string {
+	n := splitName(name)
+
+	if n.Pkg == "" {
+		return n.Type
+	}
+
+	return n.Pkg + "_" + n.Type
+}
+
+// CamelCaseNaming is a naming function that joins the package name and the
+// name of a type in camel case (e.g. 'cacheLRU' for
+// 'vimagination.zapto.org/cache.LRU').
+func CamelCaseNaming(name string) string {
+	n := splitName(name)
+
+	return camelCase(n.Pkg, n.Type)
+}
+
+// CamelCaseConversion is a conversion naming function that joins the verb and
+// local type name in camel case (e.g. 'makeCacheLRU').
+func CamelCaseConversion(verb, typeName string) string {
+	return camelCase(verb, typeName)
+}
+
+func underscoreConversion(verb, typeName string) string {
+	return verb + "_" + typeName
+}
+
+func camelCase(parts ...string) string {
+	var sb strings.Builder
+
+	for _, part := range parts {
+		for word := range strings.SplitSeq(part, "_") {
+			if word == "" {
+				continue
+			} else if sb.Len() == 0 {
+				sb.WriteString(word)
+			} else {
+				sb.WriteString(title(word))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func title(word string) string {
+	if word == "" {
+		return ""
+	}
+
+	r, size := utf8.DecodeRuneInString(word)
+
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+// TemplateNaming creates a naming function from a text/template, which is
+// executed with a Name (e.g. '{{.Pkg}}{{title .Type}}'). The 'title' function
+// upper-cases the first letter of its argument, and the 'camel' function joins
+// its arguments in camel case.
+//
+// A name for which the template fails to execute, or executes to an invalid
+// identifier, causes generation to fail with ErrInvalidNaming.
+func TemplateNaming(text string) (func(string) string, error) {
+	tmpl, err := template.New("").Funcs(template.FuncMap{
+		"title": title,
+		"camel": camelCase,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidNaming, err)
+	}
+
+	if err := tmpl.Execute(new(strings.Builder), Name{}); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidNaming, err)
+	}
+
+	return func(name string) string {
+		var sb strings.Builder
+
+		if err := tmpl.Execute(&sb, splitName(name)); err != nil {
+			return ""
+		}
+
+		return strings.Map(identRune, sb.String())
+	}, nil
+}
+
+func (b *builder) declare(local, name string) string {
+	if b.nameErr == nil {
+		if local == "_" || !token.IsIdentifier(local) || types.Universe.Lookup(local) != nil {
+			b.nameErr = fmt.Errorf("%w: %s is named %q", ErrInvalidNaming, name, local)
+		} else if prev, ok := b.names[local]; ok && prev != name {
+			b.nameErr = fmt.Errorf("%w: %s and %s are both named %s", ErrNameCollision, prev, name, local)
+		}
+	}
+
+	b.names[local] = name
+
+	return local
+}
+
+// checkImportNames reports an error if a local name is the same as the name of
+// a package imported by the generated file, which would fail to compile.
+func (b *builder) checkImportNames() error {
+	for _, imp := range sortedValues(b.imports) {
+		if name, ok := b.names[imp.Ident.Name]; ok {
+			return fmt.Errorf("%w: %s and package %s are both named %s", ErrNameCollision, name, imp.Path(), imp.Ident.Name)
+		}
+	}
+
+	return nil
+}
+
+var (
+	ErrInvalidNaming = errors.New("invalid naming")
+	ErrNameCollision = errors.New("name collision")
+)
@@ -0,0 +1,188 @@
+package generator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/module"
+)
+
+func TestSplitName(t *testing.T) {
+	for n, test := range [...]struct {
+		input string
+		name  Name
+	}{
+		{"strings.Reader", Name{"strings", "strings", "Reader"}},
+		{"vimagination.zapto.org/cache.LRU", Name{"vimagination.zapto.org/cache", "cache", "LRU"}},
+		{"vimagination.zapto.org/cache.LRU[string,*go/token.File]", Name{"vimagination.zapto.org/cache", "cache", "LRU_string_0_1go_token_File"}},
+		{"gopkg.in/yaml.v3.Node", Name{"gopkg.in/yaml.v3", "yaml", "Node"}},
+		{"github.com/a/b-c/v2.T", Name{"github.com/a/b-c/v2", "b_c", "T"}},
+		{"strings.Reader.Len", Name{"strings", "strings", "Reader_Len"}},
+		{"os.File:file.pfd", Name{"os", "os", "File_file_pfd"}},
+		{"int", Name{Type: "int"}},
+	} {
+		if name := splitName(test.input); name != test.name {
+			t.Errorf("test %d: expecting name %v, got %v", n+1, test.name, name)
+		}
+	}
+}
+
+func TestNaming(t *testing.T) {
+	camel, err := TemplateNaming("{{.Pkg}}{{title .Type}}")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	local, err := TemplateNaming("Local{{camel .Type}}")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for n, test := range [...]struct {
+		input                            string
+		short, camel, template, exported string
+	}{
+		{"strings.Reader", "strings_Reader", "stringsReader", "stringsReader", "LocalReader"},
+		{"vimagination.zapto.org/cache.LRU[string,int]", "cache_LRU_string_0int", "cacheLRUString0int", "cacheLRU_string_0int", "LocalLRUString0int"},
+		{"go/token.fileSet", "token_fileSet", "tokenFileSet", "tokenFileSet", "LocalfileSet"},
+		{"vimagination.zapto.org/cache.LRU[*go/token.File,[]int]", "cache_LRU_1go_token_File_0_2_3int", "cacheLRU1goTokenFile023int", "cacheLRU_1go_token_File_0_2_3int", "LocalLRU1goTokenFile023int"},
+		{"a.b__c", "a_b__c", "aBC", "aB__c", "LocalbC"},
+	} {
+		if name := ShortNaming(test.input); name != test.short {
+			t.Errorf("test %d: expecting short name %q, got %q", n+1, test.short, name)
+		}
+
+		if name := CamelCaseNaming(test.input); name != test.camel {
+			t.Errorf("test %d: expecting camel case name %q, got %q", n+1, test.camel, name)
+		}
+
+		if name := camel(test.input); name != test.template {
+			t.Errorf("test %d: expecting template name %q, got %q", n+1, test.template, name)
+		}
+
+		if name := local(test.input); name != test.exported {
+			t.Errorf("test %d: expecting template name %q, got %q", n+1, test.exported, name)
+		}
+	}
+
+	for n, tmpl := range [...]string{"{{.Pkg", "{{.Unknown}}", "{{unknown .Type}}"} {
+		if _, err := TemplateNaming(tmpl); !errors.Is(err, ErrInvalidNaming) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, ErrInvalidNaming, err)
+		}
+	}
+}
+
+func TestCamelCase(t *testing.T) {
+	for n, test := range [...]struct {
+		parts []string
+		camel string
+	}{
+		{[]string{"make", "cache_LRU"}, "makeCacheLRU"},
+		{[]string{"a__b", "_c_"}, "aBC"},
+		{[]string{"", "_a"}, "a"},
+		{[]string{"", "__"}, ""},
+	} {
+		if camel := camelCase(test.parts...); camel != test.camel {
+			t.Errorf("test %d: expecting %q, got %q", n+1, test.camel, camel)
+		}
+	}
+
+	if word := title(""); word != "" {
+		t.Errorf("expecting empty title, got %q", word)
+	}
+}
+
+func TestNamingCollision(t *testing.T) {
+	dir := buildPackage(t, module.Version{Path: "image"}, "Rectangle")
+
+	for n, test := range [...]struct {
+		opts     []Option
+		contains []string
+		err      error
+	}{
+		{
+			opts:     []Option{Naming(CamelCaseNaming), ConversionNaming(CamelCaseConversion)},
+			contains: []string{"type imagePoint struct", "type imageRectangle struct", "func makeImageRectangle(x *image.Rectangle) *imageRectangle", "func unmakeImageRectangle(x *imageRectangle) *image.Rectangle"},
+		},
+		{
+			opts:     []Option{Naming(ShortNaming), Rename("image.Point", "point")},
+			contains: []string{"type point struct", "type image_Rectangle struct", "func make_point(x *image.Point) *point", "func make_image_Rectangle(x *image.Rectangle) *image_Rectangle"},
+		},
+		{
+			opts: []Option{Naming(func(string) string { return "local" })},
+			err:  ErrNameCollision,
+		},
+		{
+			opts: []Option{Rename("image.Point", "image_Rectangle")},
+			err:  ErrNameCollision,
+		},
+		{
+			opts: []Option{Rename("image.Rectangle", "make_image_Point"), Rename("image.Point", "image_Point")},
+			err:  ErrNameCollision,
+		},
+		{
+			opts: []Option{Rename("image.Point", "1 bad")},
+			err:  ErrInvalidNaming,
+		},
+		{
+			opts: []Option{Rename("image.Point", "type")},
+			err:  ErrInvalidNaming,
+		},
+		{
+			opts: []Option{Rename("image.Point", "int")},
+			err:  ErrInvalidNaming,
+		},
+		{
+			opts: []Option{Rename("image.Point", "image")},
+			err:  ErrNameCollision,
+		},
+		{
+			opts: []Option{Rename("image.Point", "unsafe")},
+			err:  ErrNameCollision,
+		},
+		{
+			opts: []Option{Naming(func(string) string { return "" })},
+			err:  ErrInvalidNaming,
+		},
+		{
+			opts: []Option{Naming(mustTemplateNaming(t, "{{if .Pkg}}{{slice .Type 99}}{{end}}"))},
+			err:  ErrInvalidNaming,
+		},
+		{
+			opts: []Option{Naming(mustTemplateNaming(t, "{{if eq .Type \"Point\"}}{{.Type}}{{end}}"))},
+			err:  ErrInvalidNaming,
+		},
+		{
+			opts: []Option{ConversionNaming(func(string, string) string { return "func" })},
+			err:  ErrInvalidNaming,
+		},
+	} {
+		g, err := New(dir, test.opts...)
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		}
+
+		output, err := g.Generate("image.Rectangle", "image.Point")
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		}
+
+		for _, str := range test.contains {
+			if !strings.Contains(string(output), str) {
+				t.Errorf("test %d: expecting output to contain %q, got:\n%s", n+1, str, output)
+			}
+		}
+	}
+}
+
+func mustTemplateNaming(t *testing.T, text string) func(string) string {
+	t.Helper()
+
+	fn, err := TemplateNaming(text)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return fn
+}
@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"iter"
+	"slices"
+	"strings"
+)
+
+var (
+	byteType       = types.Universe.Lookup("byte").Type()
+	alignmentTypes = [...]types.Type{types.Typ[types.Int8], types.Typ[types.Int16], types.Typ[types.Int32], types.Typ[types.Int64]}
+)
+
+func cutFields(typeName string) (string, []string, bool) {
+	if !strings.HasSuffix(typeName, "}") {
+		return typeName, nil, false
+	}
+
+	open := strings.LastIndexByte(typeName, '{')
+	if open < 0 {
+		return typeName, nil, false
+	}
+
+	var fields []string
+
+	for field := range strings.SplitSeq(typeName[open+1:len(typeName)-1], ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	return typeName[:open], fields, true
+}
+
+func checkFields(typeName string, typ types.Type, fields []string) error {
+	if namedType, ok := typ.(*types.Named); ok && namedType.TypeParams() != nil && !isConcrete(namedType) {
+		return fmt.Errorf("%w: %s", ErrGenericOffsets, typeName)
+	}
+
+	str, ok := typ.Underlying().(*types.Struct)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotStruct, typeName)
+	}
+
+	for _, field := range fields {
+		if field == "_" || !slices.ContainsFunc(slices.Collect(str.Fields()), func(v *types.Var) bool { return v.Name() == field }) {
+			return fmt.Errorf("%w: %s.%s", ErrNoField, typeName, field)
+		}
+	}
+
+	return nil
+}
+
+func (b *builder) paddedStruct(str *types.Struct, keep []string) (*ast.StructType, *types.Struct) {
+	var (
+		vars    = slices.Collect(str.Fields())
+		offsets = b.sizes.Offsetsof(vars)
+		fields  []*ast.Field
+		layout  []*types.Var
+		offset  int64
+	)
+
+	for n, v := range vars {
+		if !slices.Contains(keep, v.Name()) {
+			continue
+		}
+
+		fields, layout = b.appendPadding(fields, layout, vars, offsets, offset, offsets[n])
+		field := b.structFieldList(func() iter.Seq[*types.Var] { return slices.Values(vars[n : n+1]) }, false)[0]
+		field.Tag = b.fieldTag(str.Tag(n), token.IsExported(fieldName(field)))
+		fields = append(fields, field)
+		layout = append(layout, v)
+		offset = offsets[n] + b.sizes.Sizeof(v.Type())
+	}
+
+	fields, layout = b.appendPadding(fields, layout, vars, offsets, offset, b.sizes.Sizeof(str))
+
+	if strAlign := b.sizes.Alignof(str); strAlign > b.sizes.Alignof(types.NewStruct(layout, nil)) {
+		for _, typ := range alignmentTypes {
+			if b.sizes.Alignof(typ) == strAlign {
+				alignFields, alignLayout := b.appendArray(nil, nil, typ, 0)
+				fields = append(alignFields, fields...)
+				layout = append(alignLayout, layout...)
+
+				break
+			}
+		}
+	}
+
+	return &ast.StructType{
+		Fields: &ast.FieldList{
+			List: fields,
+		},
+	}, types.NewStruct(layout, nil)
+}
+
+func (b *builder) layout(decl *ast.GenDecl, typ types.Type) types.Type {
+	if str, ok := b.layouts[decl.Specs[0].(*ast.TypeSpec).Name.Name]; ok {
+		return str
+	}
+
+	return typ
+}
+
+var (
+	ErrNotStruct      = errors.New("not a struct type")
+	ErrGenericOffsets = errors.New("cannot compute offsets of generic type")
+)
@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"errors"
+	"go/build"
+	"go/format"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/module"
+	"vimagination.zapto.org/gotypes"
+)
+
+func TestCutFields(t *testing.T) {
+	for n, test := range [...]struct {
+		input, typeName string
+		fields          []string
+		ok              bool
+	}{
+		{"a.b", "a.b", nil, false},
+		{"a.b{}", "a.b", nil, true},
+		{"a.b{c}", "a.b", []string{"c"}, true},
+		{"a.b[int]{c, d}", "a.b[int]", []string{"c", "d"}, true},
+	} {
+		if typeName, fields, ok := cutFields(test.input); typeName != test.typeName || !reflect.DeepEqual(fields, test.fields) || ok != test.ok {
+			t.Errorf("test %d: expecting %q, %v, %v, got %q, %v, %v", n+1, test.typeName, test.fields, test.ok, typeName, fields, ok)
+		}
+	}
+}
+
+func TestPaddedStruct(t *testing.T) {
+	for n, test := range [...]struct {
+		input, arch string
+		keep        []string
+		output      string
+		err         error
+	}{
+		{"package a\n\ntype a struct { b int8; c int64; d int16; e string }", "amd64", []string{"c"}, "type a struct {\n\t_ [8]byte\n\tc int64\n\t_ [8]byte\n\t_ unsafe.Pointer\n\t_ [8]byte\n}", nil},
+		{"package a\n\ntype a struct { b int8; c int64; d int16; e string }", "amd64", []string{"d"}, "type a struct {\n\t_ [16]byte\n\td int16\n\t_ [6]byte\n\t_ unsafe.Pointer\n\t_ [8]byte\n}", nil},
+		{"package a\n\ntype a struct { b int8; c int64; d int16; e string }", "386", []string{"d"}, "type a struct {\n\t_ [12]byte\n\td int16\n\t_ [2]byte\n\t_ unsafe.Pointer\n\t_ [4]byte\n}", nil},
+		{"package a\n\ntype a struct { b int8; c int64; d int16; e string }", "amd64", []string{"b", "e"}, "type a struct {\n\tb int8\n\t_ [23]byte\n\te string\n}", nil},
+		{"package a\n\ntype a struct { b int8; c int64 }", "amd64", nil, "type a struct {\n\t_ [0]int64\n\t_ [16]byte\n}", nil},
+		{"package a\n\ntype a struct { B int8 `json:\"b\"`; c struct{ d *a } }", "amd64", []string{"B"}, "type a struct {\n\tB int8 `json:\"b\"`\n\t_ [7]byte\n\t_ unsafe.Pointer\n}", nil},
+		{"package a\n\ntype a struct { b int8 }", "amd64", []string{"c"}, "", ErrNoField},
+		{"package a\n\ntype a [2]int", "amd64", nil, "", ErrNotStruct},
+		{"package a\n\ntype a[T any] struct { b T }", "amd64", nil, "", ErrGenericOffsets},
+	} {
+		var (
+			buf strings.Builder
+			b   builder
+		)
+
+		self := parseType(t, test.input)
+
+		b.sizes = types.SizesFor("gc", test.arch)
+		b.init()
+		b.mod = &gotypes.ModFile{Imports: map[string]module.Version{}}
+
+		if err := checkFields("a.a", self, test.keep); !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		b.keep["a"] = test.keep
+		str := b.conStruct("a", self)
+
+		b.genImports()
+		format.Node(&buf, token.NewFileSet(), str)
+
+		if str := buf.String(); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		} else if layout := b.layouts["a"]; b.sizes.Sizeof(layout) != b.sizes.Sizeof(self) || b.sizes.Alignof(layout) != b.sizes.Alignof(self) {
+			t.Errorf("test %d: layout size or alignment mismatch", n+1)
+		}
+	}
+}
+
+func TestPaddedArch(t *testing.T) {
+	dir := buildPackage(t, module.Version{Path: "strings"}, "Reader")
+
+	for n, test := range [...]struct {
+		opts     []Option
+		typeName string
+		arch     string
+	}{
+		{[]Option{GenerateComment("-o", "file.go", "strings.Reader")}, "strings.Reader", ""},
+		{[]Option{GenerateComment("-o", "file.go", "strings.Reader{i}")}, "strings.Reader{i}", build.Default.GOARCH},
+		{[]Option{Platform("linux", "386"), GenerateComment("-o", "file.go", "strings.Reader{i}")}, "strings.Reader{i}", ""},
+	} {
+		g, err := New(dir, test.opts...)
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		}
+
+		out, err := g.GenerateOutput(test.typeName)
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		} else if out.PaddedArch != test.arch {
+			t.Errorf("test %d: expecting padded architecture %q, got %q", n+1, test.arch, out.PaddedArch)
+		} else if hasComment := strings.Contains(string(out.Source), generateComment); hasComment != (test.arch == "") {
+			t.Errorf("test %d: expecting go:generate comment to be %v, got:\n%s", n+1, test.arch == "", out.Source)
+		} else if test.arch != "" && !strings.HasPrefix(string(out.Source), buildConstraint+test.arch+"\n") {
+			t.Errorf("test %d: expecting build constraint for %s, got:\n%s", n+1, test.arch, out.Source)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package generator
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+)
+
+// appendPadding appends fields that cover the bytes of the given struct fields
+// between the from and to offsets.
+//
+// Words that hold pointers are padded with unsafe.Pointer so that the garbage
+// collector still scans them; all other bytes are padded with byte arrays.
+func (b *builder) appendPadding(fields []*ast.Field, layout []*types.Var, vars []*types.Var, offsets []int64, from, to int64) ([]*ast.Field, []*types.Var) {
+	if from >= to {
+		return fields, layout
+	}
+
+	var (
+		ptrs []int64
+		word = b.sizes.Sizeof(types.Typ[types.UnsafePointer])
+		pos  = from
+	)
+
+	for n, v := range vars {
+		if offsets[n] >= from && offsets[n] < to {
+			ptrs = b.appendPointerWords(ptrs, v.Type(), offsets[n])
+		}
+	}
+
+	for start := 0; start < len(ptrs); {
+		end := start + 1
+
+		for end < len(ptrs) && ptrs[end] == ptrs[end-1]+word {
+			end++
+		}
+
+		if pad := ptrs[start] - pos; pad > 0 {
+			fields, layout = b.appendArray(fields, layout, byteType, pad)
+		}
+
+		if end-start == 1 {
+			fields = append(fields, &ast.Field{Names: blankName, Type: b.fieldToType(types.Typ[types.UnsafePointer])})
+			layout = append(layout, types.NewField(token.NoPos, nil, "_", types.Typ[types.UnsafePointer], false))
+		} else {
+			fields, layout = b.appendArray(fields, layout, types.Typ[types.UnsafePointer], int64(end-start))
+		}
+
+		pos = ptrs[end-1] + word
+		start = end
+	}
+
+	if pad := to - pos; pad > 0 {
+		fields, layout = b.appendArray(fields, layout, byteType, pad)
+	}
+
+	b.padded = true
+
+	return fields, layout
+}
+
+func (b *builder) appendArray(fields []*ast.Field, layout []*types.Var, elem types.Type, length int64) ([]*ast.Field, []*types.Var) {
+	typ := types.NewArray(elem, length)
+
+	return append(fields, &ast.Field{
+			Names: blankName,
+			Type: &ast.ArrayType{
+				Len: &ast.BasicLit{
+					Kind:  token.INT,
+					Value: strconv.FormatInt(length, 10),
+				},
+				Elt: b.fieldToType(elem),
+			},
+		}),
+		append(layout, types.NewField(token.NoPos, nil, "_", typ, false))
+}
+
+func (b *builder) appendPointerWords(ptrs []int64, typ types.Type, offset int64) []int64 {
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		if t.Kind() == types.UnsafePointer || t.Kind() == types.String {
+			ptrs = append(ptrs, offset)
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature:
+		ptrs = append(ptrs, offset)
+	case *types.Interface:
+		ptrs = append(ptrs, offset, offset+b.sizes.Sizeof(types.Typ[types.UnsafePointer]))
+	case *types.Array:
+		if !b.hasPointers(t.Elem()) {
+			break
+		}
+
+		size := b.sizes.Sizeof(t.Elem())
+
+		for n := range t.Len() {
+			ptrs = b.appendPointerWords(ptrs, t.Elem(), offset+n*size)
+		}
+	case *types.Struct:
+		vars := make([]*types.Var, 0, t.NumFields())
+
+		for field := range t.Fields() {
+			vars = append(vars, field)
+		}
+
+		for n, fieldOffset := range b.sizes.Offsetsof(vars) {
+			ptrs = b.appendPointerWords(ptrs, vars[n].Type(), offset+fieldOffset)
+		}
+	}
+
+	return ptrs
+}
+
+func (b *builder) hasPointers(typ types.Type) bool {
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		return t.Kind() == types.UnsafePointer || t.Kind() == types.String
+	case *types.Array:
+		return t.Len() > 0 && b.hasPointers(t.Elem())
+	case *types.Struct:
+		for field := range t.Fields() {
+			if b.hasPointers(field.Type()) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAppendPadding(t *testing.T) {
+	for n, test := range [...]struct {
+		input, arch string
+		from, to    int64
+		output      string
+	}{
+		{"package a\n\ntype a struct { b int8; c int64 }", "amd64", 0, 16, "struct {\n\t_ [16]byte\n}"},
+		{"package a\n\ntype a struct { b int8; c *int; d string }", "amd64", 0, 32, "struct {\n\t_ [8]byte\n\t_ [2]unsafe.Pointer\n\t_ [8]byte\n}"},
+		{"package a\n\ntype a struct { b any; c [2]*int; d [3]uint16 }", "amd64", 0, 38, "struct {\n\t_ [4]unsafe.Pointer\n\t_ [6]byte\n}"},
+		{"package a\n\ntype a struct { b [2]struct{ c int32; d []int }; e func() }", "386", 0, 36, "struct {\n\t_ [4]byte\n\t_ unsafe.Pointer\n\t_ [12]byte\n\t_ unsafe.Pointer\n\t_ [8]byte\n\t_ unsafe.Pointer\n}"},
+		{"package a\n\ntype a struct { b map[int]int; c chan int; d [1 << 20]byte }", "amd64", 8, 16, "struct {\n\t_ unsafe.Pointer\n}"},
+		{"package a\n\ntype a struct { b int8; c int64 }", "amd64", 8, 8, "struct {\n}"},
+	} {
+		var (
+			buf strings.Builder
+			b   builder
+		)
+
+		str := parseType(t, test.input).Underlying().(*types.Struct)
+		vars := slices.Collect(str.Fields())
+
+		b.sizes = types.SizesFor("gc", test.arch)
+		b.init()
+
+		fields, layout := b.appendPadding(nil, nil, vars, b.sizes.Offsetsof(vars), test.from, test.to)
+
+		format.Node(&buf, token.NewFileSet(), &ast.StructType{Fields: &ast.FieldList{List: fields}})
+
+		if str := buf.String(); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		} else if size := paddingSize(b.sizes, layout); size != test.to-test.from {
+			t.Errorf("test %d: expecting padding size %d, got %d", n+1, test.to-test.from, size)
+		} else if b.padded != (test.to > test.from) {
+			t.Errorf("test %d: expecting padded to be %v", n+1, test.to > test.from)
+		}
+	}
+}
+
+func paddingSize(sizes types.Sizes, layout []*types.Var) int64 {
+	if len(layout) == 0 {
+		return 0
+	}
+
+	return sizes.Offsetsof(layout)[len(layout)-1] + sizes.Sizeof(layout[len(layout)-1].Type())
+}
+
+func TestPaddedConstraint(t *testing.T) {
+	for n, test := range [...]struct {
+		goos, goarch, sizesArch string
+		padded                  bool
+		constraint              string
+	}{
+		{"", "", "amd64", false, ""},
+		{"", "", "amd64", true, "amd64"},
+		{"linux", "386", "", true, "linux && 386"},
+	} {
+		b := builder{goos: test.goos, goarch: test.goarch, sizesArch: test.sizesArch, padded: test.padded}
+
+		if constraint := b.constraint(); constraint != test.constraint {
+			t.Errorf("test %d: expecting constraint %q, got %q", n+1, test.constraint, constraint)
+		}
+	}
+}
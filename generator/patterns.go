@@ -0,0 +1,163 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type pattern struct {
+	pkg  string
+	glob string
+	re   *regexp.Regexp
+}
+
+func parsePattern(name string) (*pattern, error) {
+	if strings.HasSuffix(name, "/") {
+		if pos := strings.Index(name, "./"); pos > 0 && pos+2 < len(name) {
+			re, err := regexp.Compile(name[pos+2 : len(name)-1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrInvalidPattern, name, err)
+			}
+
+			return &pattern{pkg: name[:pos], re: re}, nil
+		}
+	}
+
+	if strings.ContainsAny(name, ":({") {
+		return nil, nil
+	}
+
+	typeArgs := strings.IndexByte(name, '[')
+	if typeArgs < 0 {
+		typeArgs = len(name)
+	}
+
+	pos := strings.LastIndexByte(name[:typeArgs], '.')
+	if pos < 0 || isInstantiation(name[pos+1:], typeArgs-pos-1) || !strings.ContainsAny(name[pos+1:], "*?[") {
+		return nil, nil
+	}
+
+	if _, err := path.Match(name[pos+1:], ""); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidPattern, name, err)
+	}
+
+	return &pattern{pkg: name[:pos], glob: name[pos+1:]}, nil
+}
+
+// isInstantiation reports whether the type arguments, starting at the given
+// position, follow a complete type name, rather than being a glob character
+// class.
+func isInstantiation(name string, typeArgs int) bool {
+	return typeArgs < len(name) && token.IsIdentifier(name[:typeArgs]) && strings.HasSuffix(name, "]")
+}
+
+func (p *pattern) match(name string) bool {
+	if p.re != nil {
+		return p.re.MatchString(name)
+	}
+
+	matched, _ := path.Match(p.glob, name)
+
+	return matched
+}
+
+func (b *builder) expandPatterns(imps map[string]*types.Package, typeNames []string) ([]string, error) {
+	excludes := make([]*pattern, len(b.exclude))
+
+	for n, exclude := range b.exclude {
+		p, err := parsePattern(exclude)
+		if err != nil {
+			return nil, err
+		}
+
+		excludes[n] = p
+	}
+
+	var (
+		expanded []string
+		seen     = make(map[string]struct{})
+	)
+
+	for _, typeName := range typeNames {
+		p, err := parsePattern(typeName)
+		if err != nil {
+			return nil, err
+		}
+
+		names := []string{typeName}
+
+		if p != nil {
+			if names, err = b.matchTypes(imps, p); err != nil {
+				return nil, fmt.Errorf("%w: %s", err, typeName)
+			}
+		}
+
+		var matched bool
+
+		for _, name := range names {
+			if b.isExcluded(excludes, name) {
+				continue
+			}
+
+			matched = true
+
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				expanded = append(expanded, name)
+			}
+		}
+
+		if !matched && p != nil {
+			return nil, fmt.Errorf("%w: %s", ErrNoMatch, typeName)
+		}
+	}
+
+	return expanded, nil
+}
+
+func (b *builder) matchTypes(imps map[string]*types.Package, p *pattern) ([]string, error) {
+	if !b.internal && isInternal(p.pkg) {
+		return nil, ErrInternal
+	}
+
+	pkg, ok := imps[p.pkg]
+	if !ok {
+		return nil, ErrNoModule
+	}
+
+	var names []string
+
+	for _, name := range pkg.Scope().Names() {
+		if tn, ok := pkg.Scope().Lookup(name).(*types.TypeName); ok && !tn.IsAlias() && !isConstraint(tn.Type()) && p.match(name) {
+			names = append(names, p.pkg+"."+name)
+		}
+	}
+
+	return names, nil
+}
+
+func (b *builder) isExcluded(excludes []*pattern, typeName string) bool {
+	pos := strings.LastIndexByte(typeName, '.')
+
+	for n, exclude := range excludes {
+		if exclude == nil {
+			if b.exclude[n] == typeName {
+				return true
+			}
+		} else if pos > 0 && exclude.pkg == typeName[:pos] && exclude.match(typeName[pos+1:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	ErrInvalidPattern = errors.New("invalid type pattern")
+	ErrNoMatch        = errors.New("pattern matched no types")
+)
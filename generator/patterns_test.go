@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"vimagination.zapto.org/gotypes"
+)
+
+func TestParsePattern(t *testing.T) {
+	for n, test := range [...]struct {
+		input   string
+		pkg     string
+		glob    string
+		re      string
+		pattern bool
+		err     error
+	}{
+		{input: "strings.Reader"},
+		{input: "os.File:file.pfd"},
+		{input: "(*strings.Reader).Len"},
+		{input: "go/types.Package{path,name}"},
+		{input: "strings.*", pkg: "strings", glob: "*", pattern: true},
+		{input: "net.conn*", pkg: "net", glob: "conn*", pattern: true},
+		{input: "vimagination.zapto.org/cache.?RU", pkg: "vimagination.zapto.org/cache", glob: "?RU", pattern: true},
+		{input: "net/http./^(Client|Server)$/", pkg: "net/http", re: "^(Client|Server)$", pattern: true},
+		{input: "net./conn.*/", pkg: "net", re: "conn.*", pattern: true},
+		{input: "strings.Builder[int]"},
+		{input: "vimagination.zapto.org/cache.LRU[string,*net/http.Client]"},
+		{input: "net.conn[AB]*", pkg: "net", glob: "conn[AB]*", pattern: true},
+		{input: "strings.[BR]*", pkg: "strings", glob: "[BR]*", pattern: true},
+		{input: "net.*Conn[^.]", pkg: "net", glob: "*Conn[^.]", pattern: true},
+		{input: "net.conn[", err: ErrInvalidPattern},
+		{input: "net./[/", err: ErrInvalidPattern},
+		{input: `net.*\`, err: ErrInvalidPattern},
+	} {
+		p, err := parsePattern(test.input)
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if (p != nil) != test.pattern {
+			t.Errorf("test %d: expecting pattern to be %v", n+1, test.pattern)
+		} else if p == nil {
+			continue
+		} else if p.pkg != test.pkg {
+			t.Errorf("test %d: expecting package %q, got %q", n+1, test.pkg, p.pkg)
+		} else if p.glob != test.glob {
+			t.Errorf("test %d: expecting glob %q, got %q", n+1, test.glob, p.glob)
+		} else if p.re == nil && test.re != "" || p.re != nil && p.re.String() != test.re {
+			t.Errorf("test %d: expecting regexp %q, got %v", n+1, test.re, p.re)
+		}
+	}
+}
+
+func TestExpandPatterns(t *testing.T) {
+	dir := buildModule(t, map[string]string{
+		"go.mod":     "module a\n\ngo 1.25.5\n",
+		"a.go":       "package a\n\nimport (\n\t_ \"a/dep\"\n\t_ \"cmp\"\n\t_ \"image\"\n\t_ \"net\"\n\t_ \"strings\"\n)\n",
+		"dep/dep.go": "package dep\n\ntype Num interface{ ~int | ~float64 }\n\ntype Point struct{ x, y int }\n\ntype Stringer interface{ String() string }\n",
+	})
+
+	g, err := New(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	imps := gotypes.Imports(g.pkg)
+
+	for n, test := range [...]struct {
+		input    []string
+		exclude  []string
+		expanded []string
+		err      error
+	}{
+		{
+			input:    []string{"image.Rectangle", "image.Point"},
+			expanded: []string{"image.Rectangle", "image.Point"},
+		},
+		{
+			input:    []string{"image.Gray*"},
+			expanded: []string{"image.Gray", "image.Gray16"},
+		},
+		{
+			input:    []string{"image.Point", "image./^RGBA/"},
+			expanded: []string{"image.Point", "image.RGBA", "image.RGBA64", "image.RGBA64Image"},
+		},
+		{
+			input:    []string{"image./^RGBA/", "image.RGBA"},
+			exclude:  []string{"image.*Image"},
+			expanded: []string{"image.RGBA", "image.RGBA64"},
+		},
+		{
+			input:    []string{"image.Point", "image.Gray*"},
+			exclude:  []string{"image.Point", "image.Gray16"},
+			expanded: []string{"image.Gray"},
+		},
+		{
+			input:    []string{"image.[CG]ray*"},
+			exclude:  []string{"image.Gray[0-9]*"},
+			expanded: []string{"image.Gray"},
+		},
+		{
+			input:    []string{"net.*Conn"},
+			exclude:  []string{"net.UnixConn"},
+			expanded: []string{"net.Conn", "net.IPConn", "net.PacketConn", "net.TCPConn", "net.UDPConn", "net.rawConn"},
+		},
+		{
+			input:    []string{"strings.*Replacer"},
+			expanded: []string{"strings.Replacer", "strings.byteReplacer", "strings.byteStringReplacer", "strings.genericReplacer", "strings.singleStringReplacer"},
+		},
+		{
+			input:    []string{"a/dep.*"},
+			expanded: []string{"a/dep.Point", "a/dep.Stringer"},
+		},
+		{
+			input: []string{"cmp.*"},
+			err:   ErrNoMatch,
+		},
+		{
+			input: []string{"image.Unknown*"},
+			err:   ErrNoMatch,
+		},
+		{
+			input:   []string{"image.Gray*"},
+			exclude: []string{"image./^Gray/"},
+			err:     ErrNoMatch,
+		},
+		{
+			input: []string{"net/http.*"},
+			err:   ErrNoModule,
+		},
+		{
+			input: []string{"internal/poll.*"},
+			err:   ErrInternal,
+		},
+	} {
+		g.exclude = test.exclude
+
+		if expanded, err := g.expandPatterns(imps, test.input); !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if !reflect.DeepEqual(expanded, test.expanded) {
+			t.Errorf("test %d: expecting types %v, got %v", n+1, test.expanded, expanded)
+		} else if err == nil {
+			output, err := g.Generate(test.input...)
+			if err != nil {
+				t.Errorf("test %d: unexpected error: %s", n+1, err)
+			} else {
+				vetOutput(t, dir, output)
+			}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"go/version"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// parsePackage parses the package in the given directory, as
+// gotypes.ParsePackage does with build.Default, but with the files of it, and
+// of its imports, selected with the given build context.
+func parsePackage(ctx *build.Context, dir string) (*types.Package, error) {
+	bp, err := ctx.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &contextImporter{
+		ctx:      ctx,
+		fset:     token.NewFileSet(),
+		sizes:    types.SizesFor("gc", ctx.GOARCH),
+		packages: make(map[string]*types.Package),
+	}
+
+	return c.check(bp, false)
+}
+
+// contextImporter imports packages from source, using its build context to
+// locate them and select their files.
+type contextImporter struct {
+	ctx      *build.Context
+	fset     *token.FileSet
+	sizes    types.Sizes
+	packages map[string]*types.Package
+}
+
+func (c *contextImporter) Import(path string) (*types.Package, error) {
+	return c.ImportFrom(path, ".", 0)
+}
+
+func (c *contextImporter) ImportFrom(path, dir string, _ types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	bp, err := c.ctx.Import(path, dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if pkg, ok := c.packages[bp.ImportPath]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("%w: %s", ErrImportCycle, bp.ImportPath)
+		}
+
+		return pkg, nil
+	}
+
+	c.packages[bp.ImportPath] = nil
+
+	pkg, err := c.check(bp, true)
+	if err != nil {
+		delete(c.packages, bp.ImportPath)
+
+		return nil, err
+	}
+
+	c.packages[bp.ImportPath] = pkg
+
+	return pkg, nil
+}
+
+// check type checks the files of the given package. As cgo cannot be run
+// here, references to package C in its cgo files are left unresolved.
+func (c *contextImporter) check(bp *build.Package, ignoreFuncBodies bool) (*types.Package, error) {
+	var files []*ast.File
+
+	for _, name := range slices.Concat(bp.GoFiles, bp.CgoFiles) {
+		file, err := parser.ParseFile(c.fset, filepath.Join(bp.Dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	conf := types.Config{
+		Importer:         c,
+		Sizes:            c.sizes,
+		IgnoreFuncBodies: ignoreFuncBodies,
+		FakeImportC:      len(bp.CgoFiles) > 0,
+	}
+
+	return conf.Check(bp.ImportPath, c.fset, files, nil)
+}
+
+func (b *builder) buildContext(ctx build.Context) build.Context {
+	host := ctx
+
+	if b.goos != "" {
+		ctx.GOOS = b.goos
+		ctx.GOARCH = b.goarch
+		ctx.CgoEnabled = host.CgoEnabled && b.goos == runtime.GOOS && b.goarch == runtime.GOARCH
+	}
+
+	if b.goroot != "" {
+		ctx.GOROOT = b.goroot
+		ctx.ReleaseTags = releaseTags(b.goVersion)
+	}
+
+	ctx.ToolTags = toolTags(ctx, host.ToolTags)
+
+	return ctx
+}
+
+func contextEnv(ctx build.Context) []string {
+	cgo := "0"
+
+	if ctx.CgoEnabled {
+		cgo = "1"
+	}
+
+	return append(os.Environ(), "GOOS="+ctx.GOOS, "GOARCH="+ctx.GOARCH, "CGO_ENABLED="+cgo, "GOROOT="+ctx.GOROOT, "GOTOOLCHAIN=local")
+}
+
+func (b *builder) constraint() string {
+	var terms []string
+
+	if b.goos != "" {
+		terms = append(terms, b.goos, b.goarch)
+	} else if arch := b.paddedArch(); arch != "" {
+		terms = append(terms, arch)
+	}
+
+	if b.goroot != "" {
+		terms = append(terms, version.Lang(b.goVersion))
+
+		if b.goUntil != "" {
+			terms = append(terms, "!"+version.Lang(b.goUntil))
+		}
+	}
+
+	return strings.Join(terms, " && ")
+}
+
+// paddedArch returns the GOARCH that the generated file is constrained to
+// because the layout of its padded structs is specific to it, when no platform
+// has been set.
+func (b *builder) paddedArch() string {
+	if b.padded && b.goos == "" {
+		return b.sizesArch
+	}
+
+	return ""
+}
+
+func toolTags(ctx build.Context, hostTags []string) []string {
+	cmd := exec.Command(filepath.Join(ctx.GOROOT, "bin", "go"), "list", "-f", "{{join context.ToolTags \",\"}}", "unsafe")
+	cmd.Env = contextEnv(ctx)
+
+	if out, err := cmd.Output(); err == nil {
+		return strings.Split(strings.TrimSpace(string(out)), ",")
+	}
+
+	var tags []string
+
+	for _, tag := range hostTags {
+		if ctx.GOARCH == runtime.GOARCH || !strings.HasPrefix(tag, runtime.GOARCH+".") && !strings.HasPrefix(tag, "goexperiment.regabi") {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+func releaseTags(goVersion string) []string {
+	var tags []string
+
+	for minor := 1; version.Compare(fmt.Sprintf("go1.%d", minor), goVersion) <= 0; minor++ {
+		tags = append(tags, fmt.Sprintf("go1.%d", minor))
+	}
+
+	return tags
+}
+
+var (
+	ErrUnknownPlatform  = errors.New("unknown platform")
+	ErrInvalidGoVersion = errors.New("invalid go version")
+	ErrImportCycle      = errors.New("import cycle")
+)
@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"go/build"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+
+	"golang.org/x/mod/module"
+)
+
+func TestBuildContext(t *testing.T) {
+	host := build.Default
+	goos, hasGOOS := os.LookupEnv("GOOS")
+	b := builder{goos: "plan9", goarch: "arm", goroot: host.GOROOT, goVersion: "go1.21"}
+	ctx := b.buildContext(host)
+
+	if ctx.GOOS != "plan9" || ctx.GOARCH != "arm" || ctx.CgoEnabled {
+		t.Errorf("expecting plan9/arm without cgo, got %s/%s, cgo %v", ctx.GOOS, ctx.GOARCH, ctx.CgoEnabled)
+	}
+
+	if !slices.Equal(ctx.ReleaseTags, releaseTags("go1.21")) {
+		t.Errorf("expecting release tags %v, got %v", releaseTags("go1.21"), ctx.ReleaseTags)
+	}
+
+	if build.Default.GOOS != host.GOOS || build.Default.GOARCH != host.GOARCH || !slices.Equal(build.Default.ReleaseTags, host.ReleaseTags) {
+		t.Errorf("build.Default was modified")
+	}
+
+	if env, ok := os.LookupEnv("GOOS"); env != goos || ok != hasGOOS {
+		t.Errorf("GOOS environment variable was modified")
+	}
+}
+
+func TestConcurrentGenerators(t *testing.T) {
+	dir := buildPackage(t, module.Version{Path: "strings"}, "Reader")
+	arch := build.Default.GOARCH
+
+	var wg sync.WaitGroup
+
+	for n := range 8 {
+		wg.Go(func() {
+			opts := []Option{Platform("plan9", "arm")}
+
+			if n%2 == 0 {
+				opts = nil
+			}
+
+			g, err := New(dir, opts...)
+			if err != nil {
+				t.Errorf("test %d: unexpected error: %s", n+1, err)
+			} else if g.init(); n%2 == 0 && g.sizesArch != arch {
+				t.Errorf("test %d: expecting host architecture %s, got %s", n+1, arch, g.sizesArch)
+			}
+		})
+	}
+
+	wg.Wait()
+}
+
+func TestParsePackage(t *testing.T) {
+	dir := buildModule(t, map[string]string{
+		"go.mod":     "module a\n\ngo 1.25.5",
+		"a_linux.go": "package a\n\ntype A int8",
+		"a_plan9.go": "package a\n\ntype A int16",
+	})
+
+	for n, test := range [...]struct {
+		goos, typ string
+	}{
+		{"linux", "int8"},
+		{"plan9", "int16"},
+	} {
+		b := builder{goos: test.goos, goarch: "arm"}
+		ctx := b.buildContext(build.Default)
+
+		pkg, err := parsePackage(&ctx, dir)
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %s", n+1, err)
+		} else if typ := pkg.Scope().Lookup("A").Type().Underlying().String(); typ != test.typ {
+			t.Errorf("test %d: expecting type %s, got %s", n+1, test.typ, typ)
+		}
+	}
+}
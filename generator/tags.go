@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// fieldTag returns the tag for a field of a localised struct.
+//
+// As encoding/json and encoding/xml ignore unexported fields, and go vet
+// reports such fields being tagged for them, json and xml tags are dropped from
+// unexported fields.
+func (b *builder) fieldTag(tag string, exported bool) *ast.BasicLit {
+	if b.tags != nil {
+		tag = rewriteTag(tag, b.tags)
+	}
+
+	if !exported {
+		tag = mapTag(tag, func(key string) (string, bool) {
+			return key, key != "json" && key != "xml"
+		})
+	}
+
+	if tag == "" {
+		return nil
+	}
+
+	value := strconv.Quote(tag)
+
+	if strconv.CanBackquote(tag) {
+		value = "`" + tag + "`"
+	}
+
+	return &ast.BasicLit{
+		Kind:  token.STRING,
+		Value: value,
+	}
+}
+
+func rewriteTag(tag string, rewrites map[string]string) string {
+	return mapTag(tag, func(key string) (string, bool) {
+		to, ok := rewrites[key]
+
+		return to, ok
+	})
+}
+
+// mapTag rebuilds a tag, keeping only the keys for which the given function
+// returns true, renamed to the returned key.
+func mapTag(tag string, fn func(string) (string, bool)) string {
+	var parts []string
+
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " ")
+
+		colon := strings.Index(tag, ":\"")
+		if colon <= 0 || strings.ContainsAny(tag[:colon], " \"") {
+			break
+		}
+
+		key := tag[:colon]
+
+		value, err := strconv.QuotedPrefix(tag[colon+1:])
+		if err != nil {
+			break
+		}
+
+		tag = tag[colon+1+len(value):]
+
+		if to, ok := fn(key); ok {
+			parts = append(parts, to+":"+value)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRewriteTag(t *testing.T) {
+	for n, test := range [...]struct {
+		tag      string
+		rewrites map[string]string
+		output   string
+	}{
+		{`json:"a"`, map[string]string{}, ""},
+		{`json:"a"`, map[string]string{"json": "json"}, `json:"a"`},
+		{`json:"a,omitempty" xml:"b"`, map[string]string{"xml": "xml"}, `xml:"b"`},
+		{`json:"a,omitempty" xml:"b"`, map[string]string{"json": "yaml", "xml": "xml"}, `yaml:"a,omitempty" xml:"b"`},
+		{`json:"a \"b\""  xml:"c"`, map[string]string{"xml": "x"}, `x:"c"`},
+		{`not a tag`, map[string]string{"not": "not"}, ""},
+	} {
+		if output := rewriteTag(test.tag, test.rewrites); output != test.output {
+			t.Errorf("test %d: expecting tag %q, got %q", n+1, test.output, output)
+		}
+	}
+}
+
+func TestGenerateTags(t *testing.T) {
+	dir := buildModule(t, map[string]string{
+		"go.mod":     "module a\n\ngo 1.25.5\n",
+		"a.go":       "package a\n\nimport _ \"a/dep\"\n",
+		"dep/dep.go": "package dep\n\ntype T struct {\n\tA int `json:\"a\" yaml:\"a\"`\n\tb string `yaml:\"b\" xml:\"b\"`\n\tc struct {\n\t\tD bool `json:\"d\"`\n\t}\n}\n",
+	})
+
+	for n, test := range [...]struct {
+		rewrites map[string]string
+		contains []string
+	}{
+		{nil, []string{"\tA int    `json:\"a\" yaml:\"a\"`\n", "\tb string `yaml:\"b\"`\n", "\t\tD bool `json:\"d\"`\n"}},
+		{map[string]string{"yaml": "json"}, []string{"\tA int `json:\"a\"`\n", "\tb string\n", "\t\tD bool\n"}},
+	} {
+		g, err := New(dir, TagRewrites(test.rewrites))
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		}
+
+		output, err := g.Generate("a/dep.T")
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		}
+
+		for _, str := range test.contains {
+			if !strings.Contains(string(output), str) {
+				t.Errorf("test %d: expecting output to contain %q, got:\n%s", n+1, str, output)
+			}
+		}
+
+		vetOutput(t, dir, output)
+	}
+}
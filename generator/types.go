@@ -0,0 +1,874 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"iter"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"vimagination.zapto.org/gotypes"
+)
+
+func (b *builder) lookupObject(imps map[string]*types.Package, typename string) (types.Object, error) {
+	pos := strings.LastIndexByte(typename, '.')
+	if pos < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoModuleType, typename)
+	}
+
+	if !b.internal && isInternal(typename[:pos]) {
+		return nil, ErrInternal
+	}
+
+	pkg, ok := imps[typename[:pos]]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoModule, typename[:pos])
+	}
+
+	obj := pkg.Scope().Lookup(typename[pos+1:])
+	if obj == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoType, typename)
+	}
+
+	return obj, nil
+}
+
+func (b *builder) getStruct(imps map[string]*types.Package, typename string) (types.Type, error) {
+	typ, pkg, err := b.lookupType(imps, typename)
+	if err != nil {
+		return nil, err
+	}
+
+	if isConstraint(typ) {
+		return nil, fmt.Errorf("%w: %s", ErrConstraint, typename)
+	}
+
+	if !isOpaque(typ) {
+		b.imports[pkg.Path()] = &packageName{pkg, ast.NewIdent("")}
+	}
+
+	return typ, nil
+}
+
+// isConstraint reports whether the type is an interface that can only be used
+// as a type constraint, such as one containing a type union.
+func isConstraint(typ types.Type) bool {
+	iface, ok := typ.Underlying().(*types.Interface)
+
+	return ok && !iface.IsMethodSet()
+}
+
+func (b *builder) lookupType(imps map[string]*types.Package, typename string) (types.Type, *types.Package, error) {
+	base, args, isInstance := strings.Cut(typename, "[")
+
+	obj, err := b.lookupObject(imps, base)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, ok := obj.(*types.TypeName); !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotType, typename)
+	}
+
+	if !isInstance {
+		return obj.Type(), obj.Pkg(), nil
+	}
+
+	typ, err := b.instantiate(imps, obj.Type(), args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", err, typename)
+	}
+
+	return typ, obj.Pkg(), nil
+}
+
+func (b *builder) instantiate(imps map[string]*types.Package, typ types.Type, args string) (types.Type, error) {
+	args, ok := strings.CutSuffix(args, "]")
+	if !ok {
+		return nil, ErrInvalidTypeArgs
+	}
+
+	var targs []types.Type
+
+	for _, arg := range splitTypeArgs(args) {
+		targ, err := b.parseType(imps, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		targs = append(targs, targ)
+	}
+
+	return types.Instantiate(nil, typ, targs, true)
+}
+
+func splitTypeArgs(args string) []string {
+	var (
+		parts []string
+		depth int
+		last  int
+	)
+
+	for n, c := range args {
+		switch c {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, args[last:n])
+				last = n + 1
+			}
+		}
+	}
+
+	return append(parts, args[last:])
+}
+
+func closingBracket(typ string) int {
+	depth := 0
+
+	for n, c := range typ {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			if depth == 0 {
+				return n
+			}
+
+			depth--
+		}
+	}
+
+	return -1
+}
+
+func (b *builder) parseType(imps map[string]*types.Package, typ string) (types.Type, error) {
+	typ = strings.TrimSpace(typ)
+
+	switch {
+	case strings.HasPrefix(typ, "*"):
+		elem, err := b.parseType(imps, typ[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		return types.NewPointer(elem), nil
+	case strings.HasPrefix(typ, "[]"):
+		elem, err := b.parseType(imps, typ[2:])
+		if err != nil {
+			return nil, err
+		}
+
+		return types.NewSlice(elem), nil
+	case strings.HasPrefix(typ, "["):
+		length, elemType, _ := strings.Cut(typ[1:], "]")
+
+		l, err := strconv.ParseInt(length, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTypeArgs, typ)
+		}
+
+		elem, err := b.parseType(imps, elemType)
+		if err != nil {
+			return nil, err
+		}
+
+		return types.NewArray(elem, l), nil
+	case strings.HasPrefix(typ, "map["):
+		end := closingBracket(typ[4:])
+		if end < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTypeArgs, typ)
+		}
+
+		keyType, valueType := typ[4:4+end], typ[5+end:]
+
+		key, err := b.parseType(imps, keyType)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := b.parseType(imps, valueType)
+		if err != nil {
+			return nil, err
+		}
+
+		return types.NewMap(key, value), nil
+	case strings.HasPrefix(typ, "chan "):
+		elem, err := b.parseType(imps, typ[5:])
+		if err != nil {
+			return nil, err
+		}
+
+		return types.NewChan(types.SendRecv, elem), nil
+	}
+
+	if obj, ok := types.Universe.Lookup(typ).(*types.TypeName); ok {
+		return obj.Type(), nil
+	}
+
+	t, _, err := b.lookupType(imps, typ)
+
+	return t, err
+}
+
+func (b *builder) conStruct(name string, str types.Type) *ast.GenDecl {
+	var paramList *ast.FieldList
+
+	switch typ := str.(type) {
+	case *types.Named:
+		if tp := typ.TypeParams(); tp != nil && !isConcrete(typ) {
+			paramList = new(ast.FieldList)
+
+			for t := range tp.TypeParams() {
+				paramList.List = append(paramList.List, &ast.Field{
+					Names: []*ast.Ident{ast.NewIdent(t.Obj().Name())},
+					Type:  b.fieldToType(t.Constraint()),
+				})
+			}
+		}
+
+		str = typ.Underlying()
+	}
+
+	var (
+		local = b.typeName(name)
+		typ   ast.Expr
+	)
+
+	if keep, ok := b.keep[name]; ok {
+		typ, b.layouts[local] = b.paddedStruct(str.(*types.Struct), keep)
+	} else {
+		typ = b.fieldToType(str)
+	}
+
+	return &ast.GenDecl{
+		Tok: token.TYPE,
+		Specs: []ast.Spec{
+			&ast.TypeSpec{
+				Name:       ast.NewIdent(local),
+				TypeParams: paramList,
+				Type:       typ,
+			},
+		},
+	}
+}
+
+func typeName(name string) string {
+	base, args, rest := cutTypeArgs(name)
+
+	return identName(base) + typeArgsName(args) + identName(rest)
+}
+
+func identName(name string) string {
+	var sb strings.Builder
+
+	for _, r := range name {
+		if r == '_' {
+			sb.WriteString("__")
+		} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('_')
+		}
+	}
+
+	return sb.String()
+}
+
+func cutTypeArgs(name string) (string, string, string) {
+	start := strings.IndexByte(name, '[')
+	if start < 0 {
+		return name, "", ""
+	}
+
+	depth := 0
+
+	for n, c := range name[start:] {
+		if c == '[' {
+			depth++
+		} else if c == ']' {
+			if depth--; depth == 0 {
+				return name[:start], name[start+1 : start+n], name[start+n+1:]
+			}
+		}
+	}
+
+	return name[:start], name[start+1:], ""
+}
+
+var typeArgEscapes = map[rune]string{
+	',': "_0",
+	'*': "_1",
+	'[': "_2",
+	']': "_3",
+	' ': "_4",
+	'(': "_5",
+	')': "_6",
+	'{': "_7",
+	'}': "_8",
+}
+
+// typeArgsName converts a list of type arguments into an identifier suffix.
+//
+// Underscores are doubled and package separators become single underscores,
+// as with the rest of the name, so that an underscore followed by a digit is
+// free to escape other punctuation, keeping distinct type arguments distinct
+// (e.g. '*int' becomes '_1int' and '[]int' becomes '_2_3int').
+func typeArgsName(args string) string {
+	if args == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	for n, r := range args {
+		if n == 0 && unicode.IsLetter(r) {
+			sb.WriteByte('_')
+		}
+
+		if esc, ok := typeArgEscapes[r]; ok {
+			sb.WriteString(esc)
+		} else if r == '_' {
+			sb.WriteString("__")
+		} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		} else if (r == '.' || r == '/') && !followedByDigit(args, n) {
+			sb.WriteByte('_')
+		} else {
+			escapeRune(&sb, r)
+		}
+	}
+
+	return sb.String()
+}
+
+func followedByDigit(name string, n int) bool {
+	return n+1 < len(name) && name[n+1] >= '0' && name[n+1] <= '9'
+}
+
+func escapeRune(sb *strings.Builder, r rune) {
+	fmt.Fprintf(sb, "_9%06x", r)
+}
+
+func identRune(r rune) rune {
+	if r == ']' {
+		return -1
+	} else if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		return '_'
+	}
+
+	return r
+}
+
+func (b *builder) typeName(name string) string {
+	local, ok := b.renames[name]
+	if !ok {
+		if local, ok = b.locals[name]; !ok {
+			local = b.naming(name)
+			b.locals[name] = local
+		}
+	}
+
+	return b.declare(local, name)
+}
+
+func (b *builder) newTypeName(name *types.TypeName) *ast.Ident {
+	return ast.NewIdent(b.typeName(name.Pkg().Path() + "." + name.Name()))
+}
+
+func namedTypeName(namedType *types.Named) string {
+	name := namedType.Obj().Pkg().Path() + "." + namedType.Obj().Name()
+
+	if isConcrete(namedType) {
+		var args []string
+
+		for arg := range namedType.TypeArgs().Types() {
+			args = append(args, types.TypeString(arg, nil))
+		}
+
+		name += "[" + strings.Join(args, ",") + "]"
+	}
+
+	return name
+}
+
+func isConcrete(namedType *types.Named) bool {
+	if namedType.TypeArgs().Len() == 0 {
+		return false
+	}
+
+	for arg := range namedType.TypeArgs().Types() {
+		if containsTypeParam(arg) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsTypeParam(typ types.Type) bool {
+	switch t := types.Unalias(typ).(type) {
+	case *types.TypeParam:
+		return true
+	case *types.Named:
+		for arg := range t.TypeArgs().Types() {
+			if containsTypeParam(arg) {
+				return true
+			}
+		}
+	case *types.Pointer:
+		return containsTypeParam(t.Elem())
+	case *types.Slice:
+		return containsTypeParam(t.Elem())
+	case *types.Array:
+		return containsTypeParam(t.Elem())
+	case *types.Chan:
+		return containsTypeParam(t.Elem())
+	case *types.Map:
+		return containsTypeParam(t.Key()) || containsTypeParam(t.Elem())
+	case *types.Struct:
+		for field := range t.Fields() {
+			if containsTypeParam(field.Type()) {
+				return true
+			}
+		}
+	case *types.Signature:
+		for v := range t.Params().Variables() {
+			if containsTypeParam(v.Type()) {
+				return true
+			}
+		}
+
+		for v := range t.Results().Variables() {
+			if containsTypeParam(v.Type()) {
+				return true
+			}
+		}
+	case *types.Interface:
+		for method := range t.Methods() {
+			if containsTypeParam(method.Type()) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (b *builder) structFieldList(fieldsFn func() iter.Seq[*types.Var], variadic bool) []*ast.Field {
+	var fields []*ast.Field
+
+	for field := range fieldsFn() {
+		var name []*ast.Ident
+
+		typ := b.fieldToType(field.Type())
+
+		if field.Embedded() {
+			typ = b.embeddedType(field.Type(), typ)
+		} else if n := field.Name(); n != "" {
+			name = []*ast.Ident{ast.NewIdent(field.Name())}
+		}
+
+		fields = append(fields, &ast.Field{
+			Names: name,
+			Type:  typ,
+		})
+	}
+
+	if variadic && len(fields) > 0 {
+		fields[len(fields)-1].Type = &ast.UnaryExpr{
+			Op: token.ELLIPSIS,
+			X:  fields[len(fields)-1].Type.(*ast.ArrayType).Elt,
+		}
+	}
+
+	return fields
+}
+
+func (b *builder) embeddedType(typ types.Type, expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if namedType, ok := types.Unalias(typ).(*types.Named); ok && e.Name != namedType.Obj().Name() && b.names[e.Name] != namedTypeName(namedType) {
+			return b.embeddedTypeName(namedType)
+		}
+
+		return expr
+	case *ast.SelectorExpr, *ast.IndexListExpr:
+		return expr
+	case *ast.StarExpr:
+		if ptr, ok := typ.(*types.Pointer); ok {
+			return &ast.StarExpr{X: b.embeddedType(ptr.Elem(), e.X)}
+		}
+	}
+
+	if namedType, ok := types.Unalias(typ).(*types.Named); ok {
+		return b.embeddedTypeName(namedType)
+	}
+
+	return expr
+}
+
+// embeddedTypeName localises a type that is only localised because it is
+// embedded. Unless it has been renamed, or already named, it keeps its
+// original name, so that the name of the embedded field is unchanged, falling
+// back to the naming function when that name is already taken.
+func (b *builder) embeddedTypeName(namedType *types.Named) ast.Expr {
+	name := namedTypeName(namedType)
+
+	if _, ok := b.renames[name]; !ok {
+		if _, ok := b.locals[name]; !ok {
+			if local := namedType.Obj().Name(); types.Universe.Lookup(local) == nil {
+				if _, ok := b.names[local]; !ok {
+					b.locals[name] = local
+				}
+			}
+		}
+	}
+
+	return b.requiredTypeName(namedType)
+}
+
+func fieldName(field *ast.Field) string {
+	if len(field.Names) > 0 {
+		return field.Names[0].Name
+	}
+
+	return embeddedName(field.Type)
+}
+
+func embeddedName(typ ast.Expr) string {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	}
+
+	return ""
+}
+
+func (b *builder) requiredTypeName(namedType *types.Named) ast.Expr {
+	name := namedTypeName(namedType)
+	b.required = append(b.required, named{name, namedType})
+
+	return ast.NewIdent(b.typeName(name))
+}
+
+func (b *builder) fieldToType(typ types.Type) ast.Expr {
+	if expr := b.handleNamed(typ); expr != nil {
+		return expr
+	}
+
+	if namedType, isNamed := typ.(*types.Named); isNamed && gotypes.IsTypeRecursive(typ) {
+		return b.requiredTypeName(namedType)
+	}
+
+	switch t := typ.Underlying().(type) {
+	case *types.Pointer:
+		return &ast.StarExpr{
+			X: b.fieldToType(t.Elem()),
+		}
+	case *types.Map:
+		return &ast.MapType{
+			Key:   b.fieldToType(t.Key()),
+			Value: b.fieldToType(t.Elem()),
+		}
+	case *types.Array:
+		return &ast.ArrayType{
+			Len: &ast.BasicLit{
+				Value: strconv.FormatInt(t.Len(), 10),
+			},
+			Elt: b.fieldToType(t.Elem()),
+		}
+	case *types.Slice:
+		return &ast.ArrayType{
+			Elt: b.fieldToType(t.Elem()),
+		}
+	case *types.Chan:
+		return b.chanType(t)
+	case *types.Struct:
+		fields := b.structFieldList(t.Fields, false)
+
+		for n, field := range fields {
+			field.Tag = b.fieldTag(t.Tag(n), token.IsExported(fieldName(field)))
+		}
+
+		return &ast.StructType{
+			Fields: &ast.FieldList{
+				List: fields,
+			},
+		}
+	case *types.Signature:
+		return &ast.FuncType{
+			Params: &ast.FieldList{
+				List: b.structFieldList(t.Params().Variables, t.Variadic()),
+			},
+			Results: &ast.FieldList{
+				List: b.structFieldList(t.Results().Variables, false),
+			},
+		}
+	case *types.Interface:
+		if t.IsImplicit() && t.NumEmbeddeds() == 1 {
+			return b.fieldToType(t.EmbeddedType(0))
+		} else if t.NumMethods() == 0 && t.IsMethodSet() {
+			return ast.NewIdent("any")
+		}
+
+		if namedType, isNamed := typ.(*types.Named); isNamed && (namedType.TypeArgs() != nil || interfaceContainsUnexported(t)) {
+			return b.requiredTypeName(typ.(*types.Named))
+		}
+
+		var fields []*ast.Field
+
+		for f := range t.EmbeddedTypes() {
+			fields = append(fields, &ast.Field{
+				Type: b.fieldToType(f),
+			})
+		}
+
+		for fn := range t.ExplicitMethods() {
+			typ := b.fieldToType(fn.Signature()).(*ast.FuncType)
+
+			typ.Func = token.NoPos
+
+			fields = append(fields, &ast.Field{
+				Names: []*ast.Ident{ast.NewIdent(fn.Name())},
+				Type:  typ,
+			})
+		}
+
+		return &ast.InterfaceType{
+			Methods: &ast.FieldList{
+				List: fields,
+			},
+		}
+	case *types.Union:
+		return b.unionType(t)
+	case *types.Basic:
+		if t.Kind() == types.UnsafePointer {
+			b.unsafePointer = true
+
+			return unsafePointer
+		}
+
+		return ast.NewIdent(t.Name())
+	}
+
+	return nil
+}
+
+func (b *builder) unionType(t *types.Union) ast.Expr {
+	var union ast.Expr
+
+	for term := range t.Terms() {
+		expr := b.fieldToType(term.Type())
+
+		if term.Tilde() {
+			expr = &ast.UnaryExpr{
+				Op: token.TILDE,
+				X:  expr,
+			}
+		}
+
+		if union == nil {
+			union = expr
+		} else {
+			union = &ast.BinaryExpr{
+				X:  union,
+				Op: token.OR,
+				Y:  expr,
+			}
+		}
+	}
+
+	return union
+}
+
+func (b *builder) chanType(t *types.Chan) *ast.ChanType {
+	var (
+		dir  ast.ChanDir
+		elem = b.fieldToType(t.Elem())
+	)
+
+	switch t.Dir() {
+	case types.SendRecv:
+		dir = ast.SEND | ast.RECV
+
+		if c, ok := elem.(*ast.ChanType); ok && c.Dir == ast.RECV {
+			elem = &ast.ParenExpr{X: elem}
+		}
+	case types.SendOnly:
+		dir = ast.SEND
+	case types.RecvOnly:
+		dir = ast.RECV
+	}
+
+	return &ast.ChanType{
+		Dir:   dir,
+		Value: elem,
+	}
+}
+
+func interfaceContainsUnexported(t *types.Interface) bool {
+	for method := range t.ExplicitMethods() {
+		if !method.Exported() {
+			return true
+		}
+
+		for v := range method.Signature().Params().Variables() {
+			if named, ok := v.Type().(*types.Named); ok {
+				if !named.Obj().Exported() {
+					return true
+				}
+			}
+		}
+
+		for v := range method.Signature().Results().Variables() {
+			if named, ok := v.Type().(*types.Named); ok {
+				if !named.Obj().Exported() {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func (b *builder) handleNamed(typ types.Type) ast.Expr {
+	switch namedType := typ.(type) {
+	case *types.Named:
+		var (
+			name     ast.Expr
+			internal = namedType.Obj().Pkg() != nil && isInternal(namedType.Obj().Pkg().Path())
+		)
+
+		if namedType.Obj().Exported() && !internal {
+			name = &ast.SelectorExpr{
+				X:   b.packageName(namedType.Obj().Pkg()),
+				Sel: ast.NewIdent(namedType.Obj().Name()),
+			}
+		} else if namedType.Obj().Pkg() == nil {
+			return ast.NewIdent(namedType.Obj().Name())
+		}
+
+		if namedType.TypeParams() != nil {
+			if name == nil {
+				name = b.requiredTypeName(namedType.Origin())
+			}
+
+			indicies := make([]ast.Expr, 0, namedType.TypeArgs().Len())
+
+			for typ, param := range combineIters(namedType.Obj().Type().(*types.Named).TypeParams().TypeParams(), namedType.TypeArgs().Types()) {
+				var name *types.TypeName
+
+				switch p := param.(type) {
+				case *types.Named:
+					name = p.Obj()
+				case *types.TypeParam:
+					name = p.Obj()
+				}
+
+				fieldType := b.fieldToType(param)
+				indicies = append(indicies, fieldType)
+
+				if name != nil {
+					b.implements[b.newTypeName(name).Name] = interfaceType{typ.Underlying().(*types.Interface), param}
+				}
+			}
+
+			return &ast.IndexListExpr{
+				X:       name,
+				Indices: indicies,
+			}
+		} else if name != nil {
+			return name
+		} else if internal && b.internal {
+			return b.requiredTypeName(namedType)
+		}
+	case *types.TypeParam:
+		return ast.NewIdent(namedType.Obj().Name())
+	}
+
+	return nil
+}
+
+func (b *builder) originalType(namedType *types.Named) ast.Expr {
+	var name ast.Expr = &ast.SelectorExpr{
+		X:   b.packageName(namedType.Obj().Pkg()),
+		Sel: ast.NewIdent(namedType.Obj().Name()),
+	}
+
+	if isConcrete(namedType) {
+		name = &ast.IndexListExpr{
+			X:       name,
+			Indices: b.typeArgs(namedType),
+		}
+	}
+
+	return name
+}
+
+func (b *builder) typeArgs(namedType *types.Named) []ast.Expr {
+	indicies := make([]ast.Expr, 0, namedType.TypeArgs().Len())
+
+	for arg := range namedType.TypeArgs().Types() {
+		indicies = append(indicies, b.fieldToType(arg))
+	}
+
+	return indicies
+}
+
+func combineIters[V1, V2 any](iter1 iter.Seq[V1], iter2 iter.Seq[V2]) iter.Seq2[V1, V2] {
+	return func(yield func(V1, V2) bool) {
+		nextIter1, stopIter1 := iter.Pull(iter1)
+		nextIter2, stopIter2 := iter.Pull(iter2)
+
+		defer stopIter1()
+		defer stopIter2()
+
+		for {
+			v1, ok := nextIter1()
+			if !ok {
+				return
+			}
+
+			v2, _ := nextIter2()
+
+			if !yield(v1, v2) {
+				return
+			}
+		}
+	}
+}
+
+func (b *builder) packageName(pkg *types.Package) *ast.Ident {
+	name, ok := b.imports[pkg.Path()]
+	if !ok {
+		name = &packageName{pkg, ast.NewIdent("")}
+		b.imports[pkg.Path()] = name
+	}
+
+	return name.Ident
+}
+
+var (
+	ErrNoModuleType    = errors.New("module-less type")
+	ErrNoModule        = errors.New("module not imported")
+	ErrNoType          = errors.New("no type found")
+	ErrNotType         = errors.New("not a type")
+	ErrInternal        = errors.New("cannot process internal type")
+	ErrInvalidTypeArgs = errors.New("invalid type arguments")
+	ErrConstraint      = errors.New("cannot localise constraint interface")
+)
@@ -0,0 +1,434 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/module"
+	"vimagination.zapto.org/gotypes"
+)
+
+func TestTypeName(t *testing.T) {
+	for n, test := range [...][2]string{
+		{
+			"A", "A",
+		},
+		{
+			"a.A", "a_A",
+		},
+		{
+			"a_A", "a__A",
+		},
+		{
+			"vimagination.zapto.org/httpreaderat.Request", "vimagination_zapto_org_httpreaderat_Request",
+		},
+		{
+			"vimagination.zapto.org/cache.LRU[string,int]", "vimagination_zapto_org_cache_LRU_string_0int",
+		},
+		{
+			"a.A[*b.B,map[string][]int]", "a_A_1b_B_0map_2string_3_2_3int",
+		},
+		{
+			"a.A[*int]", "a_A_1int",
+		},
+		{
+			"a.A[[]int]", "a_A_2_3int",
+		},
+		{
+			"a.A[[2]int]", "a_A_22_3int",
+		},
+		{
+			"a.A_1int", "a_A__1int",
+		},
+		{
+			"github.com/99designs/gqlgen/graphql.Response", "github_com_99designs_gqlgen_graphql_Response",
+		},
+		{
+			"a.A[int].B", "a_A_int_B",
+		},
+	} {
+		if name := typeName(test[0]); name != test[1] {
+			t.Errorf("test %d: expecting name %q, got %q", n+1, test[1], name)
+		}
+	}
+}
+
+func TestFieldToType(t *testing.T) {
+	for n, test := range [...]struct {
+		typ types.Type
+		res string
+	}{
+		{
+			typ: types.Typ[types.Int8],
+			res: "int8",
+		},
+		{
+			typ: types.Typ[types.Bool],
+			res: "bool",
+		},
+		{
+			typ: types.NewPointer(types.Typ[types.Float32]),
+			res: "*float32",
+		},
+		{
+			typ: types.NewMap(types.Typ[types.String], types.NewSlice(types.Typ[types.Uint32])),
+			res: "map[string][]uint32",
+		},
+		{
+			typ: types.NewArray(types.Typ[types.Complex128], 3),
+			res: "[3]complex128",
+		},
+		{
+			typ: types.NewChan(types.SendRecv, types.Typ[types.Int]),
+			res: "chan int",
+		},
+		{
+			typ: types.NewChan(types.SendOnly, types.Typ[types.String]),
+			res: "chan<- string",
+		},
+		{
+			typ: types.NewChan(types.RecvOnly, types.NewChan(types.SendOnly, types.Typ[types.Bool])),
+			res: "<-chan chan<- bool",
+		},
+		{
+			typ: types.NewChan(types.SendRecv, types.NewChan(types.RecvOnly, types.Typ[types.Bool])),
+			res: "chan (<-chan bool)",
+		},
+	} {
+		var (
+			buf strings.Builder
+			b   builder
+		)
+
+		b.init()
+		format.Node(&buf, token.NewFileSet(), b.fieldToType(test.typ))
+
+		if str := buf.String(); str != test.res {
+			t.Errorf("test %d: expecting type %q, got %q", n+1, test.res, str)
+		}
+	}
+}
+
+func parseFile(t *testing.T, input string) *types.Package {
+	t.Helper()
+
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, "a.go", input, parser.AllErrors|parser.ParseComments)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	conf := types.Config{
+		GoVersion: runtime.Version(),
+		Importer:  importer.ForCompiler(fset, runtime.Compiler, nil),
+	}
+
+	pkg, err := conf.Check("a", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return pkg
+}
+
+func parseType(t *testing.T, input string) types.Type {
+	return parseFile(t, input).Scope().Lookup("a").Type()
+}
+
+func TestConStruct(t *testing.T) {
+	for n, test := range [...]struct {
+		input, output string
+	}{
+		{"package a\n\nimport \"strings\"\n\ntype a struct { r strings.Reader }", "type a struct {\n\tr strings.Reader\n}"},
+		{"package a\n\ntype a struct { a *a }", "type a struct {\n\ta *a_a\n}"},
+		{"package a\n\ntype a struct { a b }\ntype b struct { c int }", "type a struct {\n\ta struct {\n\t\tc int\n\t}\n}"},
+		{"package a\n\ntype a struct { a func(b) c }\ntype b struct { c int }\ntype c int", "type a struct {\n\ta func(struct {\n\t\tc int\n\t}) int\n}"},
+		{"package a\n\ntype a struct { a b }\ntype b interface {\n\tc\n\tA() int\n\tinterface {E()}\n}\ntype c interface {\n\tB(bool, float32) string\n}", "type a struct {\n\ta interface {\n\t\tinterface {\n\t\t\tB(bool, float32) string\n\t\t}\n\t\tinterface {\n\t\t\tE()\n\t\t}\n\t\tA() int\n\t}\n}"},
+		{"package a\n\ntype a struct { a b }\ntype b interface {C() int}", "type a struct {\n\ta interface {\n\t\tC() int\n\t}\n}"},
+		{"package a\n\ntype a struct { a b }\ntype b interface {C() b}", "type a struct {\n\ta a_b\n}"},
+		{"package a\n\ntype a struct { b }\ntype b interface {C() b}", "type a struct {\n\ta_b\n}"},
+		{"package a\n\nimport \"sync\"\n\ntype a struct { sync.Mutex; *b; c }\ntype b struct { d int }\ntype c struct { e bool }", "type a struct {\n\tsync.Mutex\n\t*b\n\tc\n}"},
+		{"package a\n\nimport \"sync/atomic\"\n\ntype a struct { atomic.Pointer[b]; c[b] }\ntype b struct { d int }\ntype c[T any] struct { e T }", "type a struct {\n\tatomic.Pointer[struct {\n\t\td int\n\t}]\n\ta_c[struct {\n\t\td int\n\t}]\n}"},
+		{"package a\n\nimport \"sync\"\n\ntype a = sync.Mutex", "type a struct {\n\t_ struct {\n\t}\n\tmu struct {\n\t\tstate int32\n\t\tsema  uint32\n\t}\n}"},
+		{"package a\n\ntype a struct { err error }", "type a struct {\n\terr error\n}"},
+		{"package a\n\ntype a struct { a any }", "type a struct {\n\ta any\n}"},
+		{"package a\n\ntype a struct { a chan b; c <-chan chan<- *a }\ntype b struct { c int }", "type a struct {\n\ta chan struct {\n\t\tc int\n\t}\n\tc <-chan chan<- *a_a\n}"},
+		{"package a\n\ntype a struct { a chan b }\ntype b <-chan c\ntype c struct { d *c }", "type a struct {\n\ta chan (<-chan a_c)\n}"},
+		{"package a\n\ntype a struct { A int `json:\"a\"`; b struct { C string `json:\"c,omitempty\" xml:\"c\"` } }", "type a struct {\n\tA int `json:\"a\"`\n\tb struct {\n\t\tC string `json:\"c,omitempty\" xml:\"c\"`\n\t}\n}"},
+		{"package a\n\ntype a struct { a int `json:\"a\" yaml:\"a\"`; b string `xml:\"b\"` }", "type a struct {\n\ta int `yaml:\"a\"`\n\tb string\n}"},
+		{"package a\n\ntype a map[string]*b\ntype b struct { c a }", "type a map[string]*a_b"},
+		{"package a\n\ntype a func(b) []int\ntype b [4]byte", "type a func([4]byte) []int"},
+		{"package a\n\ntype a []b\ntype b *b", "type a []a_b"},
+		{"package a\n\ntype a struct { c; d }\ntype c int\ntype d int", "type a struct {\n\tc\n\td\n}"},
+		{"package a\n\ntype a struct { a func(...b) c }\ntype b struct { c int }\ntype c int", "type a struct {\n\ta func(...struct {\n\t\tc int\n\t}) int\n}"},
+	} {
+		var (
+			buf strings.Builder
+			b   builder
+		)
+
+		self := parseType(t, test.input)
+		imps := gotypes.Imports(self.(interface{ Obj() *types.TypeName }).Obj().Pkg())
+
+		b.init()
+		b.mod = &gotypes.ModFile{Imports: map[string]module.Version{}}
+		b.getStruct(imps, "a")
+
+		str := b.conStruct("a", self.Underlying())
+
+		b.genImports()
+		format.Node(&buf, token.NewFileSet(), str)
+
+		if str := buf.String(); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		}
+	}
+}
+
+func TestEmbeddedLocalName(t *testing.T) {
+	dir := buildPackage(t, module.Version{Path: "go/types"}, "Func")
+
+	for n, test := range [...]struct {
+		opts     []Option
+		contains string
+	}{
+		{nil, "type go_types_Func struct {\n\tobject\n"},
+		{[]Option{Rename("go/types.object", "obj")}, "type go_types_Func struct {\n\tobj\n"},
+		{[]Option{Rename("go/types.Func", "object")}, "type object struct {\n\tgo_types_object\n"},
+	} {
+		g, err := New(dir, test.opts...)
+		if err != nil {
+			t.Fatalf("test %d: unexpected error: %s", n+1, err)
+		}
+
+		output, err := g.Generate("go/types.Func")
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %s", n+1, err)
+		} else if !strings.Contains(string(output), test.contains) {
+			t.Errorf("test %d: expecting output to contain %q, got:\n%s", n+1, test.contains, output)
+		} else {
+			vetOutput(t, dir, output)
+		}
+	}
+}
+
+func TestConStructTypeParams(t *testing.T) {
+	for n, test := range [...]struct {
+		input, output string
+	}{
+		{"package a\n\ntype a[T any] struct { b T }", "type a[T any] struct {\n\tb T\n}"},
+		{"package a\n\ntype a[T ~int] struct { b T }", "type a[T ~int] struct {\n\tb T\n}"},
+		{"package a\n\ntype a[T ~int | ~string, U int8 | uint8] struct { b T; c U }", "type a[T ~int | ~string, U int8 | uint8] struct {\n\tb T\n\tc U\n}"},
+		{"package a\n\ntype a[T b] struct { c T }\ntype b interface { ~int | ~float64 }", "type a[T interface {\n\t~int | ~float64\n}] struct {\n\tc T\n}"},
+		{"package a\n\ntype a[T b] struct { c T }\ntype b interface { comparable; ~int | ~string; String() string }", "type a[T interface {\n\tcomparable\n\t~int | ~string\n\tString() string\n}] struct {\n\tc T\n}"},
+		{"package a\n\nimport \"cmp\"\n\ntype a[T cmp.Ordered] struct { b T }", "type a[T cmp.Ordered] struct {\n\tb T\n}"},
+	} {
+		var (
+			buf strings.Builder
+			b   builder
+		)
+
+		self := parseType(t, test.input)
+
+		b.init()
+		b.mod = &gotypes.ModFile{Imports: map[string]module.Version{}}
+
+		str := b.conStruct("a", self)
+
+		b.genImports()
+		format.Node(&buf, token.NewFileSet(), str)
+
+		if str := buf.String(); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		}
+	}
+}
+
+func TestConStructInstance(t *testing.T) {
+	pkg := parseFile(t, "package a\n\nimport \"go/token\"\n\ntype a[K comparable, V any] struct { m map[K]*b[V]; p token.Pos }\ntype b[T any] struct { v T }")
+	imps := map[string]*types.Package{"a": pkg, "go/token": pkg.Imports()[0]}
+
+	for n, test := range [...]struct {
+		input, output string
+		err           error
+	}{
+		{input: "a.a[string,int]", output: "type a_a_string_0int struct {\n\tm map[string]*a_b[int]\n\tp token.Pos\n}"},
+		{input: "a.a[ *go/token.Pos, []map[string][2]int ]", output: "type a_a_1go_token_Pos_0_2_3map_2string_3_22_3int struct {\n\tm map[*token.Pos]*a_b[[]map[string][2]int]\n\tp token.Pos\n}"},
+		{input: "a.a[string", err: ErrInvalidTypeArgs},
+		{input: "a.a[string,a.c]", err: ErrNoType},
+		{input: "a.a[string,[x]int]", err: ErrInvalidTypeArgs},
+	} {
+		var (
+			buf strings.Builder
+			b   builder
+		)
+
+		b.init()
+		b.mod = &gotypes.ModFile{Imports: map[string]module.Version{}}
+
+		typ, err := b.getStruct(imps, test.input)
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		str := b.conStruct(namedTypeName(typ.(*types.Named)), typ)
+
+		b.genImports()
+		format.Node(&buf, token.NewFileSet(), str)
+
+		if str := buf.String(); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		}
+	}
+}
+
+func TestConStructInternal(t *testing.T) {
+	imps := gotypes.Imports(parseFile(t, "package a\n\nimport _ \"sync\""))
+
+	for n, test := range [...]struct {
+		input    string
+		internal bool
+		output   string
+		err      error
+	}{
+		{input: "sync.Mutex", output: "type sync_Mutex struct {\n\t_ struct {\n\t}\n\tmu struct {\n\t\tstate int32\n\t\tsema  uint32\n\t}\n}"},
+		{input: "sync.Mutex", internal: true, output: "type sync_Mutex struct {\n\t_ struct {\n\t}\n\tmu internal_sync_Mutex\n}"},
+		{input: "internal/sync.Mutex", err: ErrInternal},
+		{input: "internal/sync.Mutex", internal: true, output: "type internal_sync_Mutex struct {\n\tstate int32\n\tsema  uint32\n}"},
+	} {
+		var (
+			buf strings.Builder
+			b   builder
+		)
+
+		b.init()
+		b.mod = &gotypes.ModFile{Imports: map[string]module.Version{}}
+		b.internal = test.internal
+
+		typ, err := b.getStruct(imps, test.input)
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		str := b.conStruct(test.input, typ)
+
+		b.genImports()
+		format.Node(&buf, token.NewFileSet(), str)
+
+		if str := buf.String(); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		} else if _, ok := b.imports["internal/sync"]; ok {
+			t.Errorf("test %d: unexpected import of internal package", n+1)
+		}
+	}
+}
+
+func buildPackage(t *testing.T, imp module.Version, typeName string) string {
+	t.Helper()
+
+	var gomod, file bytes.Buffer
+
+	if imp.Version == "" {
+		fmt.Fprintf(&gomod, "module a\n\ngo 1.25.5")
+	} else {
+		fmt.Fprintf(&gomod, "module a\n\ngo 1.25.5\n\nrequire %s %s", imp.Path, imp.Version)
+	}
+
+	fmt.Fprintf(&file, "package a\n\nimport b %q\ntype c = b.%s", imp.Path, typeName)
+
+	return buildModule(t, map[string]string{
+		"go.mod": gomod.String(),
+		"a.go":   file.String(),
+	})
+}
+
+// buildModule writes the given files, keyed by their slash-separated paths, to
+// a temporary module directory, and makes it the working directory so that
+// packages within the module can be resolved.
+func buildModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	tmp := t.TempDir()
+
+	for name, contents := range files {
+		path := filepath.Join(tmp, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		} else if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	t.Chdir(tmp)
+
+	return tmp
+}
+
+func parseTypeFromImport(t *testing.T, imp module.Version, typeName string) types.Type {
+	t.Helper()
+
+	tmp := buildPackage(t, imp, typeName)
+
+	pkg, err := gotypes.ParsePackage(tmp)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return pkg.Scope().Lookup("c").Type()
+}
+
+func TestConStructFromImport(t *testing.T) {
+	for n, test := range [...]struct {
+		imp              module.Version
+		typename, output string
+	}{
+		{
+			module.Version{Path: "vimagination.zapto.org/memfs", Version: "v1.1.1"},
+			"FS",
+			"type a struct {\n\tmu   sync.RWMutex\n\tfsRO struct {\n\t\tde vimagination_zapto_org_memfs_directoryEntry\n\t}\n}",
+		},
+	} {
+		var (
+			buf strings.Builder
+			b   builder
+		)
+
+		self := parseTypeFromImport(t, test.imp, test.typename)
+		imps := gotypes.Imports(self.(interface{ Obj() *types.TypeName }).Obj().Pkg())
+
+		b.init()
+		b.mod = &gotypes.ModFile{Imports: map[string]module.Version{}}
+		b.getStruct(imps, "a")
+
+		str := b.conStruct("a", self.Underlying())
+
+		b.genImports()
+		format.Node(&buf, token.NewFileSet(), str)
+
+		if str := buf.String(); str != test.output {
+			t.Errorf("test %d: expecting output:\n%s\n\ngot:\n%s", n+1, test.output, str)
+		}
+	}
+}
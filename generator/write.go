@@ -0,0 +1,425 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/token"
+	"go/types"
+	"go/version"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"vimagination.zapto.org/gotypes"
+)
+
+// ToolPath is the module path of the unsafe command, as run by the
+// go:generate comment.
+const ToolPath = "vimagination.zapto.org/unsafe"
+
+const (
+	generateComment = "//go:generate "
+	buildConstraint = "//go:build "
+)
+
+type named struct {
+	name string
+	typ  types.Type
+}
+
+type pos []int
+
+func (p *pos) newLine() token.Pos {
+	l := len(*p)
+	*p = append(*p, len(*p), len(*p)+1)
+
+	return token.Pos(l + 1)
+}
+
+type packageName struct {
+	*types.Package
+	*ast.Ident
+}
+
+type builder struct {
+	mod           *gotypes.ModFile
+	imports       map[string]*packageName
+	structs       map[string]ast.Decl
+	implements    map[string]interfaceType
+	methods       map[string][]ast.Decl
+	linked        map[string]*types.Package
+	required      []named
+	functions     []ast.Decl
+	linknames     []ast.Decl
+	assertions    []ast.Decl
+	args          []string
+	accessors     bool
+	internal      bool
+	typeAccessors map[string]bool
+	unsafePointer bool
+	header        string
+	command       string
+	exclude       []string
+	keep          map[string][]string
+	layouts       map[string]*types.Struct
+	padded        bool
+	sizes         types.Sizes
+	sizesArch     string
+	goos, goarch  string
+	goroot        string
+	goVersion     string
+	goUntil       string
+	naming        func(string) string
+	conversion    func(string, string) string
+	renames       map[string]string
+	locals        map[string]string
+	names         map[string]string
+	nameErr       error
+	tags          map[string]string
+	pkg           *types.Package
+	pos
+}
+
+type interfaceType struct {
+	*types.Interface
+	types.Type
+}
+
+func newBuilder(module string) (*builder, error) {
+	b := new(builder)
+
+	if err := b.load(module); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (b *builder) load(module string) error {
+	if b.goos != "" || b.goarch != "" {
+		if b.sizes = types.SizesFor("gc", b.goarch); b.sizes == nil || b.goos == "" {
+			return fmt.Errorf("%w: %s/%s", ErrUnknownPlatform, b.goos, b.goarch)
+		}
+	}
+
+	if b.goroot != "" && (!version.IsValid(b.goVersion) || b.goUntil != "" && version.Compare(b.goVersion, b.goUntil) >= 0) {
+		return fmt.Errorf("%w: %s", ErrInvalidGoVersion, b.goVersion)
+	}
+
+	var (
+		pkg *types.Package
+		err error
+	)
+
+	if b.goos == "" && b.goroot == "" {
+		pkg, err = gotypes.ParsePackage(module)
+	} else {
+		ctx := b.buildContext(build.Default)
+		pkg, err = parsePackage(&ctx, module)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	mod, err := gotypes.ParseModFile(module)
+	if err != nil {
+		return err
+	}
+
+	b.pkg = pkg
+	b.mod = mod
+
+	return nil
+}
+
+func (b *builder) file(pkgName string, typeNames ...string) (*token.FileSet, *ast.File, error) {
+	if pkgName == "" {
+		pkgName = b.pkg.Name()
+	}
+
+	b.init()
+
+	file, err := b.genAST(pkgName, typeNames)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset := token.NewFileSet()
+	wsfile := fset.AddFile("out.go", 1, len(b.pos))
+
+	wsfile.SetLines(b.pos)
+
+	return fset, file, nil
+}
+
+func (b *builder) directive(pkgName string) ([]byte, error) {
+	if len(b.args) == 0 {
+		return nil, ErrNoGenerateComment
+	} else if pkgName == "" {
+		pkgName = b.pkg.Name()
+	}
+
+	b.init()
+
+	return fmt.Appendf(nil, "%s%s %s\n\npackage %s\n\n%s\n", generateComment, b.command, encodeOpts(b.args), pkgName, autoGeneratedCommand), nil
+}
+
+func (b *builder) init() {
+	b.structs = make(map[string]ast.Decl)
+	b.pos = []int{0, 1}
+	b.imports = map[string]*packageName{"unsafe": {types.NewPackage("unsafe", "unsafe"), ast.NewIdent("unsafe")}}
+	b.implements = make(map[string]interfaceType)
+	b.methods = make(map[string][]ast.Decl)
+	b.linked = make(map[string]*types.Package)
+	b.required = nil
+	b.functions = nil
+	b.linknames = nil
+	b.assertions = nil
+	b.unsafePointer = false
+
+	b.keep = make(map[string][]string)
+	b.layouts = make(map[string]*types.Struct)
+	b.padded = false
+
+	b.locals = make(map[string]string)
+	b.names = make(map[string]string)
+	b.nameErr = nil
+
+	if b.naming == nil {
+		b.naming = typeName
+	}
+
+	if b.conversion == nil {
+		b.conversion = underscoreConversion
+	}
+
+	if b.command == "" {
+		b.command = "go run " + ToolPath + "@latest"
+	}
+
+	if b.sizes == nil {
+		b.sizesArch = build.Default.GOARCH
+		b.sizes = types.SizesFor("gc", b.sizesArch)
+	}
+}
+
+func (b *builder) genAST(packageName string, typeNames []string) (*ast.File, error) {
+	imps := gotypes.Imports(b.pkg)
+
+	typeNames, err := b.expandPatterns(imps, typeNames)
+	if err != nil {
+		return nil, err
+	}
+
+	topLevel := make([]string, 0, len(typeNames))
+
+	for _, typeName := range typeNames {
+		if strings.Contains(typeName, ":") {
+			fn, err := b.buildFieldPath(imps, typeName)
+			if err != nil {
+				return nil, err
+			}
+
+			b.functions = append(b.functions, fn)
+
+			continue
+		} else if strings.HasPrefix(typeName, "(") {
+			fn, err := b.buildMethod(imps, typeName)
+			if err != nil {
+				return nil, err
+			}
+
+			b.linknames = append(b.linknames, fn)
+
+			continue
+		}
+
+		typeName, fields, offsetsOnly := cutFields(typeName)
+		base, _, _ := strings.Cut(typeName, "[")
+
+		obj, err := b.lookupObject(imps, base)
+		if err != nil {
+			return nil, err
+		}
+
+		switch obj := obj.(type) {
+		case *types.Var:
+			b.linknames = append(b.linknames, b.buildVar(typeName, obj))
+
+			continue
+		case *types.Func:
+			fn, err := b.buildFunction(typeName, obj)
+			if err != nil {
+				return nil, err
+			}
+
+			b.linknames = append(b.linknames, fn)
+
+			continue
+		}
+
+		str, err := b.getStruct(imps, typeName)
+		if err != nil {
+			return nil, err
+		}
+
+		if offsetsOnly {
+			if err := checkFields(typeName, str, fields); err != nil {
+				return nil, err
+			}
+		}
+
+		if namedType, ok := str.(*types.Named); ok && isConcrete(namedType) {
+			name := namedTypeName(namedType)
+
+			if enabled, ok := b.typeAccessors[typeName]; ok {
+				b.typeAccessors[name] = enabled
+			}
+
+			typeName = name
+		}
+
+		if offsetsOnly {
+			b.keep[typeName] = fields
+		}
+
+		topLevel = append(topLevel, typeName)
+		b.required = append(b.required, named{typeName, str})
+	}
+
+	for len(b.required) > 0 {
+		t := b.required[0]
+		b.required = b.required[1:]
+		name := t.name
+
+		if _, ok := b.structs[name]; ok {
+			continue
+		}
+
+		decl := b.conStruct(name, t.typ)
+		b.structs[name] = decl
+
+		if b.hasAccessors(name) {
+			methods, err := buildAccessors(decl, b.layout(decl, t.typ))
+			if err != nil {
+				return nil, err
+			}
+
+			b.methods[b.typeName(name)] = methods
+		}
+
+		if slices.Contains(topLevel, name) {
+			b.functions = append(b.functions, b.buildFunc(t.typ), b.buildUnmakeFunc(t.typ))
+		}
+
+		if assertion := b.buildAssertions(decl, t.typ); assertion != nil {
+			b.assertions = append(b.assertions, assertion)
+		}
+	}
+
+	if b.nameErr != nil {
+		return nil, b.nameErr
+	}
+
+	if err := b.checkAccessors(); err != nil {
+		return nil, err
+	}
+
+	var doc *ast.CommentGroup
+
+	if constraint := b.constraint(); constraint != "" {
+		doc = &ast.CommentGroup{
+			List: []*ast.Comment{
+				{
+					Slash: b.newLine(),
+					Text:  buildConstraint + constraint,
+				},
+			},
+		}
+	}
+
+	if len(b.args) > 0 && b.paddedArch() == "" {
+		if doc == nil {
+			doc = new(ast.CommentGroup)
+		}
+
+		doc.List = append(doc.List, &ast.Comment{
+			Slash: b.newLine(),
+			Text:  generateComment + b.command + " " + encodeOpts(b.args),
+		})
+	}
+
+	pkgPos := b.newLine()
+	imports := b.genImports()
+
+	if err := b.checkImportNames(); err != nil {
+		return nil, err
+	}
+
+	return &ast.File{
+		Doc:     doc,
+		Package: pkgPos,
+		Name:    ast.NewIdent(packageName),
+		Decls:   append(append(append(append([]ast.Decl{imports}, b.addNewLines(b.addAccessors(b.addRequiredMethods(sortedValues(b.structs))))...), b.addNewLines(b.functions)...), b.addNewLines(b.linknames)...), b.addNewLines(b.assertions)...),
+	}, nil
+}
+
+func encodeOpts(opts []string) string {
+	var buf []byte
+
+	for n, opt := range opts {
+		if n > 0 {
+			buf = append(buf, ' ')
+		}
+
+		if opt == "" || strings.ContainsFunc(opt, needsQuote) {
+			buf = strconv.AppendQuote(buf, opt)
+		} else {
+			buf = append(buf, opt...)
+		}
+	}
+
+	return string(buf)
+}
+
+func needsQuote(r rune) bool {
+	return r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r)
+}
+
+func (b *builder) addNewLines(decls []ast.Decl) []ast.Decl {
+	for n := range decls {
+		switch decl := decls[n].(type) {
+		case *ast.GenDecl:
+			decl.TokPos = b.declLine(decl.Doc)
+		case *ast.FuncDecl:
+			decl.Type.Func = b.declLine(decl.Doc)
+
+			if decl.Body != nil && decl.Body != emptyReturn {
+				decl.Body.Lbrace = decl.Type.Func
+				decl.Body.Rbrace = decl.Type.Func + 1
+
+				b.newLine()
+			}
+		}
+	}
+
+	return decls
+}
+
+func (b *builder) declLine(doc *ast.CommentGroup) token.Pos {
+	if doc == nil {
+		return b.newLine()
+	}
+
+	doc.List[0].Slash = b.newLine()
+
+	b.newLine()
+
+	return doc.List[0].Slash + 1
+}
+
+var ErrNoGenerateComment = errors.New("no go:generate comment set")
@@ -1,4 +1,4 @@
-package main
+package generator
 
 import (
 	"strings"
@@ -32,6 +32,30 @@ type strings_Reader struct {
 func make_strings_Reader(x *strings.Reader) *strings_Reader {
 	return (*strings_Reader)(unsafe.Pointer(x))
 }
+
+func unmake_strings_Reader(x *strings_Reader) *strings.Reader {
+	return (*strings.Reader)(unsafe.Pointer(x))
+}
+
+const (
+	_ = -(unsafe.Sizeof(strings_Reader{}) ^ unsafe.Sizeof(strings.Reader{}))
+	_ = -(unsafe.Alignof(strings_Reader{}) ^ unsafe.Alignof(strings.Reader{}))
+)
+`,
+		},
+		{
+			[]string{"strings.asciiSpace"},
+			`package e
+
+` + autoGenerated + `
+
+import (
+	_ "strings"
+	_ "unsafe"
+)
+
+//go:linkname strings_asciiSpace strings.asciiSpace
+var strings_asciiSpace [256]uint8
 `,
 		},
 		{
@@ -85,9 +109,27 @@ func make_go_types_Package(x *types.Package) *go_types_Package {
 	return (*go_types_Package)(unsafe.Pointer(x))
 }
 
+func unmake_go_types_Package(x *go_types_Package) *types.Package {
+	return (*types.Package)(unsafe.Pointer(x))
+}
+
 func make_go_token_FileSet(x *token.FileSet) *go_token_FileSet {
 	return (*go_token_FileSet)(unsafe.Pointer(x))
 }
+
+func unmake_go_token_FileSet(x *go_token_FileSet) *token.FileSet {
+	return (*token.FileSet)(unsafe.Pointer(x))
+}
+
+const (
+	_ = -(unsafe.Sizeof(go_types_Package{}) ^ unsafe.Sizeof(types.Package{}))
+	_ = -(unsafe.Alignof(go_types_Package{}) ^ unsafe.Alignof(types.Package{}))
+)
+
+const (
+	_ = -(unsafe.Sizeof(go_token_FileSet{}) ^ unsafe.Sizeof(token.FileSet{}))
+	_ = -(unsafe.Alignof(go_token_FileSet{}) ^ unsafe.Alignof(token.FileSet{}))
+)
 `,
 		},
 		{
@@ -96,11 +138,7 @@ func make_go_token_FileSet(x *token.FileSet) *go_token_FileSet {
 
 ` + autoGenerated + `
 
-import (
-	"unsafe"
-
-	"vimagination.zapto.org/httpreaderat"
-)
+import "unsafe"
 
 type vimagination_zapto_org_httpreaderat_block struct {
 	data string
@@ -108,8 +146,12 @@ type vimagination_zapto_org_httpreaderat_block struct {
 	next *vimagination_zapto_org_httpreaderat_block
 }
 
-func make_vimagination_zapto_org_httpreaderat_block(x *httpreaderat.block) *vimagination_zapto_org_httpreaderat_block {
-	return (*vimagination_zapto_org_httpreaderat_block)(unsafe.Pointer(x))
+func make_vimagination_zapto_org_httpreaderat_block(x unsafe.Pointer) *vimagination_zapto_org_httpreaderat_block {
+	return (*vimagination_zapto_org_httpreaderat_block)(x)
+}
+
+func unmake_vimagination_zapto_org_httpreaderat_block(x *vimagination_zapto_org_httpreaderat_block) unsafe.Pointer {
+	return unsafe.Pointer(x)
 }
 `,
 		},
@@ -160,17 +202,21 @@ type vimagination_zapto_org_cache_ru[T comparable, U any] interface {
 func make_vimagination_zapto_org_cache_LRU[T comparable, U any](x *cache.LRU[T, U]) *vimagination_zapto_org_cache_LRU[T, U] {
 	return (*vimagination_zapto_org_cache_LRU[T, U])(unsafe.Pointer(x))
 }
+
+func unmake_vimagination_zapto_org_cache_LRU[T comparable, U any](x *vimagination_zapto_org_cache_LRU[T, U]) *cache.LRU[T, U] {
+	return (*cache.LRU[T, U])(unsafe.Pointer(x))
+}
 `,
 		},
 	} {
-		b, err := newBuilder(".")
+		g, err := New(".", PackageName("e"))
 		if err != nil {
 			t.Fatalf("unexpected error: %s", err)
 		}
 
 		var buf strings.Builder
 
-		if err := b.WriteType(&buf, "e", test.typeName...); err != nil {
+		if err := g.WriteType(&buf, test.typeName...); err != nil {
 			t.Fatalf("unexpected error: %s", err)
 		}
 
@@ -234,6 +280,15 @@ type vimagination_zapto_org_memfs_directoryEntry interface {
 func make_vimagination_zapto_org_memfs_FS(x *memfs.FS) *vimagination_zapto_org_memfs_FS {
 	return (*vimagination_zapto_org_memfs_FS)(unsafe.Pointer(x))
 }
+
+func unmake_vimagination_zapto_org_memfs_FS(x *vimagination_zapto_org_memfs_FS) *memfs.FS {
+	return (*memfs.FS)(unsafe.Pointer(x))
+}
+
+const (
+	_ = -(unsafe.Sizeof(vimagination_zapto_org_memfs_FS{}) ^ unsafe.Sizeof(memfs.FS{}))
+	_ = -(unsafe.Alignof(vimagination_zapto_org_memfs_FS{}) ^ unsafe.Alignof(memfs.FS{}))
+)
 `,
 		},
 		{
@@ -317,19 +372,29 @@ type html_template_rangeContext struct {
 func make_html_template_Template(x *template.Template) *html_template_Template {
 	return (*html_template_Template)(unsafe.Pointer(x))
 }
+
+func unmake_html_template_Template(x *html_template_Template) *template.Template {
+	return (*template.Template)(unsafe.Pointer(x))
+}
+
+const (
+	_ = -(unsafe.Sizeof(html_template_Template{}) ^ unsafe.Sizeof(template.Template{}))
+	_ = -(unsafe.Alignof(html_template_Template{}) ^ unsafe.Alignof(template.Template{}))
+	_ = -(unsafe.Offsetof(html_template_Template{}.Tree) ^ unsafe.Offsetof(template.Template{}.Tree))
+)
 `,
 		},
 	} {
 		last := strings.LastIndexByte(test.typeName, '.')
 
-		b, err := newBuilder(buildPackage(t, test.imp, test.typeName[last+1:]), "-o", "file.go", "-p", "e")
+		g, err := New(buildPackage(t, test.imp, test.typeName[last+1:]), PackageName("e"), GenerateComment("-o", "file.go", "-p", "e"))
 		if err != nil {
 			t.Fatalf("unexpected error: %s", err)
 		}
 
 		var buf strings.Builder
 
-		if err := b.WriteType(&buf, "e", test.typeName); err != nil {
+		if err := g.WriteType(&buf, test.typeName); err != nil {
 			t.Fatalf("unexpected error: %s", err)
 		}
 
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/version"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"vimagination.zapto.org/unsafe/generator"
+)
+
+type goVersion struct {
+	version, until, goroot string
+}
+
+func parseGoVersions(list string) ([]goVersion, error) {
+	if list == "" {
+		return nil, nil
+	}
+
+	var versions []goVersion
+
+	for v := range strings.SplitSeq(list, ",") {
+		ver, goroot, _ := strings.Cut(strings.TrimSpace(v), "=")
+		if !version.IsValid(ver) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidGoVersion, v)
+		}
+
+		versions = append(versions, goVersion{version: ver, goroot: goroot})
+	}
+
+	slices.SortFunc(versions, func(a, b goVersion) int {
+		return version.Compare(a.version, b.version)
+	})
+
+	for n := 1; n < len(versions); n++ {
+		if version.Lang(versions[n-1].version) == version.Lang(versions[n].version) {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateGoVersion, version.Lang(versions[n].version))
+		}
+
+		versions[n-1].until = version.Lang(versions[n].version)
+	}
+
+	return versions, nil
+}
+
+func (g goVersion) output(output string) string {
+	return addSuffix(output, "_"+strings.ReplaceAll(version.Lang(g.version), ".", "_"))
+}
+
+// root finds the GOROOT of an installed toolchain for the version: either a
+// golang.org/dl wrapper command, the local go command, or a toolchain
+// previously downloaded to the module cache. Toolchains are never downloaded.
+func (g goVersion) root() (string, error) {
+	if g.goroot != "" {
+		return g.goroot, nil
+	}
+
+	if path, err := exec.LookPath(g.version); err == nil {
+		env, err := goEnv(path, "GOROOT")
+		if err != nil {
+			return "", fmt.Errorf("%w: %s: %w", ErrNoToolchain, g.version, err)
+		}
+
+		return env[0], nil
+	}
+
+	env, err := goEnv("go", "GOROOT", "GOVERSION", "GOMODCACHE", "GOHOSTOS", "GOHOSTARCH")
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrNoToolchain, g.version, err)
+	}
+
+	if env[1] == g.version || version.Lang(g.version) == g.version && version.Lang(env[1]) == g.version {
+		return env[0], nil
+	}
+
+	cached := filepath.Join(env[2], "golang.org", "toolchain@v0.0.1-"+g.version+"."+env[3]+"-"+env[4])
+
+	if _, err := os.Stat(filepath.Join(cached, "bin", "go")); err == nil {
+		return cached, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrNoToolchain, g.version)
+}
+
+func goEnv(goCmd string, vars ...string) ([]string, error) {
+	cmd := exec.Command(goCmd, append([]string{"env"}, vars...)...)
+	cmd.Env = append(os.Environ(), "GOTOOLCHAIN=local")
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	env := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(env) != len(vars) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidGoEnv, out)
+	}
+
+	return env, nil
+}
+
+func generateVersions(w io.Writer, dir, output string, versions []goVersion, platforms []platform, check bool, typeNames []string, opts ...generator.Option) error {
+	if len(versions) == 0 {
+		return generatePlatforms(w, dir, output, platforms, check, typeNames, opts...)
+	}
+
+	var outdated bool
+
+	for _, v := range versions {
+		goroot, err := v.root()
+		if err != nil {
+			return err
+		}
+
+		if err := generatePlatforms(w, dir, v.output(output), platforms, check, typeNames, append(opts[:len(opts):len(opts)], generator.GoVersion(goroot, version.Lang(v.version), v.until))...); errors.Is(err, ErrOutdated) {
+			outdated = true
+		} else if err != nil {
+			return fmt.Errorf("%s: %w", v.version, err)
+		}
+	}
+
+	if outdated {
+		return ErrOutdated
+	}
+
+	return nil
+}
+
+var (
+	ErrInvalidGoVersion   = errors.New("invalid go version")
+	ErrDuplicateGoVersion = errors.New("duplicate go version")
+	ErrNoToolchain        = errors.New("could not find installed toolchain")
+	ErrInvalidGoEnv       = errors.New("invalid go env output")
+)
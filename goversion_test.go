@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"go/version"
+	"reflect"
+	"testing"
+)
+
+func TestParseGoVersions(t *testing.T) {
+	for n, test := range [...]struct {
+		input    string
+		versions []goVersion
+		err      error
+	}{
+		{"", nil, nil},
+		{"go1.22", []goVersion{{"go1.22", "", ""}}, nil},
+		{"go1.23.4, go1.22.10=/opt/go1.22", []goVersion{{"go1.22.10", "go1.23", "/opt/go1.22"}, {"go1.23.4", "", ""}}, nil},
+		{"go1.22,go1.24,go1.23", []goVersion{{"go1.22", "go1.23", ""}, {"go1.23", "go1.24", ""}, {"go1.24", "", ""}}, nil},
+		{"1.22", nil, ErrInvalidGoVersion},
+		{"go1.22,", nil, ErrInvalidGoVersion},
+		{"go1.22.1,go1.22.2", nil, ErrDuplicateGoVersion},
+	} {
+		if versions, err := parseGoVersions(test.input); !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if !reflect.DeepEqual(versions, test.versions) {
+			t.Errorf("test %d: expecting versions %v, got %v", n+1, test.versions, versions)
+		}
+	}
+}
+
+func TestGoVersionOutput(t *testing.T) {
+	for n, test := range [...]struct {
+		input, output string
+	}{
+		{"a.go", "a_go1_22.go"},
+		{"a_test.go", "a_go1_22_test.go"},
+	} {
+		if output := (goVersion{version: "go1.22.5"}).output(test.input); output != test.output {
+			t.Errorf("test %d: expecting output %q, got %q", n+1, test.output, output)
+		}
+	}
+}
+
+func TestGoVersionRoot(t *testing.T) {
+	env, err := goEnv("go", "GOROOT", "GOVERSION")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for n, test := range [...]struct {
+		version goVersion
+		root    string
+		err     error
+	}{
+		{goVersion{version: "go1.22", goroot: "/opt/go1.22"}, "/opt/go1.22", nil},
+		{goVersion{version: env[1]}, env[0], nil},
+		{goVersion{version: version.Lang(env[1])}, env[0], nil},
+		{goVersion{version: "go1.2.3"}, "", ErrNoToolchain},
+	} {
+		if root, err := test.version.root(); !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if root != test.root {
+			t.Errorf("test %d: expecting root %q, got %q", n+1, test.root, root)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"vimagination.zapto.org/unsafe/generator"
+)
+
+func runInspect(w io.Writer, args []string) error {
+	var (
+		dir      string
+		asJSON   bool
+		internal bool
+	)
+
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+
+	fs.StringVar(&dir, "dir", ".", "directory of the package whose imports the types are taken from")
+	fs.BoolVar(&asJSON, "json", false, "print the layouts as JSON")
+	fs.BoolVar(&internal, "i", false, "allow inspecting types from internal packages")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return ErrNoTypes
+	}
+
+	var opts []generator.Option
+
+	if internal {
+		opts = append(opts, generator.Internal())
+	}
+
+	g, err := generator.New(dir, opts...)
+	if err != nil {
+		return err
+	}
+
+	layouts := make([]*generator.Layout, 0, fs.NArg())
+
+	for _, typeName := range fs.Args() {
+		layout, err := g.Inspect(typeName)
+		if err != nil {
+			return err
+		}
+
+		layouts = append(layouts, layout)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(w)
+
+		enc.SetIndent("", "\t")
+
+		return enc.Encode(layouts)
+	}
+
+	return printLayouts(w, layouts)
+}
+
+func printLayouts(w io.Writer, layouts []*generator.Layout) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	for n, layout := range layouts {
+		if n > 0 {
+			fmt.Fprintln(tw)
+		}
+
+		fmt.Fprintf(tw, "%s (size %d, align %d)\n", layout.Type, layout.Size, layout.Align)
+		fmt.Fprintln(tw, "OFFSET\tSIZE\tALIGN\tEXPORTED\tFIELD\tTYPE\tPACKAGE")
+
+		for _, field := range layout.Fields {
+			pkg := field.Package
+			if pkg == "" {
+				pkg = "-"
+			}
+
+			offset := strconv.FormatInt(field.Offset, 10)
+			if field.Indirect {
+				offset = "*" + offset
+			}
+
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%t\t%s%s\t%s\t%s\n", offset, field.Size, field.Align, field.Exported, strings.Repeat("  ", strings.Count(field.Path, ".")), field.Path, field.Type, pkg)
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"vimagination.zapto.org/unsafe/generator"
+)
+
+func TestPrintLayouts(t *testing.T) {
+	var sb strings.Builder
+
+	if err := printLayouts(&sb, []*generator.Layout{
+		{
+			Type:  "image.Rectangle",
+			Size:  32,
+			Align: 8,
+			Fields: []generator.Field{
+				{Path: "Min", Type: "image.Point", Package: "image", Offset: 0, Size: 16, Align: 8, Exported: true},
+				{Path: "Min.X", Type: "int", Offset: 0, Size: 8, Align: 8, Exported: true},
+				{Path: "Min.Y", Type: "int", Offset: 8, Size: 8, Align: 8, Exported: true},
+			},
+		},
+		{
+			Type:  "strings.Reader",
+			Size:  32,
+			Align: 8,
+			Fields: []generator.Field{
+				{Path: "s", Type: "string", Offset: 0, Size: 16, Align: 8},
+				{Path: "next", Type: "*a.node", Package: "a", Offset: 16, Size: 8, Align: 8},
+				{Path: "next.v", Type: "int", Offset: 0, Size: 8, Align: 8, Indirect: true},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	const expected = `image.Rectangle (size 32, align 8)
+OFFSET  SIZE  ALIGN  EXPORTED  FIELD    TYPE         PACKAGE
+0       16    8      true      Min      image.Point  image
+0       8     8      true        Min.X  int          -
+8       8     8      true        Min.Y  int          -
+
+strings.Reader (size 32, align 8)
+OFFSET  SIZE  ALIGN  EXPORTED  FIELD     TYPE     PACKAGE
+0       16    8      false     s         string   -
+16      8     8      false     next      *a.node  a
+*0      8     8      false       next.v  int      -
+`
+
+	if output := sb.String(); output != expected {
+		t.Errorf("expecting output:\n%s\n\ngot:\n%s", expected, output)
+	}
+}
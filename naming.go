@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"vimagination.zapto.org/unsafe/generator"
+)
+
+func namingOptions(scheme string, renames map[string]string) ([]generator.Option, error) {
+	var opts []generator.Option
+
+	switch scheme {
+	case "", "full":
+	case "short":
+		opts = append(opts, generator.Naming(generator.ShortNaming))
+	case "camel":
+		opts = append(opts, generator.Naming(generator.CamelCaseNaming), generator.ConversionNaming(generator.CamelCaseConversion))
+	default:
+		if !strings.Contains(scheme, "{{") {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNaming, scheme)
+		}
+
+		fn, err := generator.TemplateNaming(scheme)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, generator.Naming(fn))
+	}
+
+	for name, local := range renames {
+		if !isLocalName(local) {
+			return nil, fmt.Errorf("%w: %s=%s", ErrInvalidRename, name, local)
+		}
+
+		opts = append(opts, generator.Rename(name, local))
+	}
+
+	return opts, nil
+}
+
+func parseRenames(flag string) (map[string]string, error) {
+	if flag == "" {
+		return nil, nil
+	}
+
+	renames := make(map[string]string)
+
+	for _, rename := range splitPatterns(flag) {
+		name, local, ok := strings.Cut(rename, "=")
+		if !ok || name == "" || !isLocalName(local) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRename, rename)
+		}
+
+		renames[name] = local
+	}
+
+	return renames, nil
+}
+
+// isLocalName reports whether the name can be used to declare a localised type,
+// i.e. it is a non-blank identifier, and neither a keyword nor predeclared.
+func isLocalName(name string) bool {
+	return name != "_" && token.IsIdentifier(name) && types.Universe.Lookup(name) == nil
+}
+
+var (
+	ErrUnknownNaming = errors.New("unknown naming scheme, expecting full, short, camel or a template")
+	ErrInvalidRename = errors.New("invalid rename, expecting orig=Local")
+)
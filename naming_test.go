@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"vimagination.zapto.org/unsafe/generator"
+)
+
+func TestNamingOptions(t *testing.T) {
+	for n, test := range [...]struct {
+		scheme  string
+		renames map[string]string
+		options int
+		err     error
+	}{
+		{"", nil, 0, nil},
+		{"full", nil, 0, nil},
+		{"short", nil, 1, nil},
+		{"camel", nil, 2, nil},
+		{"{{.Pkg}}{{title .Type}}", nil, 1, nil},
+		{"short", map[string]string{"strings.Reader": "reader", "strings.Builder": "builder"}, 3, nil},
+		{"short", map[string]string{"strings.Reader": "type"}, 0, ErrInvalidRename},
+		{"short", map[string]string{"strings.Reader": "a-b"}, 0, ErrInvalidRename},
+		{"short", map[string]string{"strings.Reader": "string"}, 0, ErrInvalidRename},
+		{"unknown", nil, 0, ErrUnknownNaming},
+		{"{{.Unknown}}", nil, 0, generator.ErrInvalidNaming},
+	} {
+		if opts, err := namingOptions(test.scheme, test.renames); !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if len(opts) != test.options {
+			t.Errorf("test %d: expecting %d options, got %d", n+1, test.options, len(opts))
+		}
+	}
+}
+
+func TestParseRenames(t *testing.T) {
+	for n, test := range [...]struct {
+		input   string
+		renames map[string]string
+		err     error
+	}{
+		{"", nil, nil},
+		{"strings.Reader=reader", map[string]string{"strings.Reader": "reader"}, nil},
+		{"strings.Reader=reader,vimagination.zapto.org/cache.LRU=lru", map[string]string{"strings.Reader": "reader", "vimagination.zapto.org/cache.LRU": "lru"}, nil},
+		{"strings.Reader", nil, ErrInvalidRename},
+		{"strings.Reader=", nil, ErrInvalidRename},
+		{"=reader", nil, ErrInvalidRename},
+		{"vimagination.zapto.org/cache.LRU[string,int]=lru", map[string]string{"vimagination.zapto.org/cache.LRU[string,int]": "lru"}, nil},
+		{"strings.Reader=func", nil, ErrInvalidRename},
+		{"strings.Reader=1reader", nil, ErrInvalidRename},
+		{"strings.Reader=_", nil, ErrInvalidRename},
+		{"strings.Reader=a.b", nil, ErrInvalidRename},
+		{"strings.Reader=int", nil, ErrInvalidRename},
+		{"strings.Reader=nil", nil, ErrInvalidRename},
+	} {
+		if renames, err := parseRenames(test.input); !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if !reflect.DeepEqual(renames, test.renames) {
+			t.Errorf("test %d: expecting renames %v, got %v", n+1, test.renames, renames)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"vimagination.zapto.org/unsafe/generator"
+)
+
+type platform struct {
+	goos, goarch string
+}
+
+func parsePlatforms(list string) ([]platform, error) {
+	if list == "" {
+		return nil, nil
+	}
+
+	var platforms []platform
+
+	for p := range strings.SplitSeq(list, ",") {
+		goos, goarch, ok := strings.Cut(strings.TrimSpace(p), "/")
+		if !ok || goos == "" || goarch == "" || strings.ContainsAny(goarch, "/ ") {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPlatform, p)
+		}
+
+		platforms = append(platforms, platform{goos, goarch})
+	}
+
+	return platforms, nil
+}
+
+func (p platform) output(output string) string {
+	return addSuffix(output, "_"+p.goos+"_"+p.goarch)
+}
+
+func addSuffix(output, suffix string) string {
+	ext := filepath.Ext(output)
+	base := strings.TrimSuffix(output, ext)
+
+	if strings.HasSuffix(base, "_test") {
+		base = strings.TrimSuffix(base, "_test")
+		suffix += "_test"
+	}
+
+	return base + suffix + ext
+}
+
+func generatePlatforms(w io.Writer, dir, output string, platforms []platform, check bool, typeNames []string, opts ...generator.Option) error {
+	if len(platforms) == 0 {
+		return generate(w, dir, output, check, typeNames, opts...)
+	}
+
+	var outdated bool
+
+	for _, p := range platforms {
+		if err := generate(w, dir, p.output(output), check, typeNames, append(opts[:len(opts):len(opts)], generator.Platform(p.goos, p.goarch))...); errors.Is(err, ErrOutdated) {
+			outdated = true
+		} else if err != nil {
+			return fmt.Errorf("%s/%s: %w", p.goos, p.goarch, err)
+		}
+	}
+
+	if outdated {
+		return ErrOutdated
+	}
+
+	return nil
+}
+
+var ErrInvalidPlatform = errors.New("invalid platform, expecting GOOS/GOARCH")
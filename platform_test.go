@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParsePlatforms(t *testing.T) {
+	for n, test := range [...]struct {
+		input     string
+		platforms []platform
+		err       error
+	}{
+		{"", nil, nil},
+		{"linux/amd64", []platform{{"linux", "amd64"}}, nil},
+		{"linux/amd64, windows/386", []platform{{"linux", "amd64"}, {"windows", "386"}}, nil},
+		{"linux", nil, ErrInvalidPlatform},
+		{"linux/", nil, ErrInvalidPlatform},
+		{"linux/amd64/v2", nil, ErrInvalidPlatform},
+	} {
+		if platforms, err := parsePlatforms(test.input); !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if !reflect.DeepEqual(platforms, test.platforms) {
+			t.Errorf("test %d: expecting platforms %v, got %v", n+1, test.platforms, platforms)
+		}
+	}
+}
+
+func TestPlatformOutput(t *testing.T) {
+	for n, test := range [...]struct {
+		input, output string
+	}{
+		{"a.go", "a_linux_amd64.go"},
+		{"a_test.go", "a_linux_amd64_test.go"},
+		{"dir/a.b.go", "dir/a.b_linux_amd64.go"},
+		{"a_go1_22_test.go", "a_go1_22_linux_amd64_test.go"},
+	} {
+		if output := (platform{"linux", "amd64"}).output(test.input); output != test.output {
+			t.Errorf("test %d: expecting output %q, got %q", n+1, test.output, output)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func parseTagRewrites(flag string) (map[string]string, error) {
+	if flag == "" {
+		return nil, nil
+	}
+
+	rewrites := make(map[string]string)
+
+	if flag == "-" {
+		return rewrites, nil
+	}
+
+	for rewrite := range strings.SplitSeq(flag, ",") {
+		from, to, ok := strings.Cut(rewrite, "=")
+		if !ok {
+			to = from
+		}
+
+		if from == "" || to == "" || strings.ContainsAny(rewrite, " \t\":`") {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTagRewrite, rewrite)
+		}
+
+		rewrites[from] = to
+	}
+
+	return rewrites, nil
+}
+
+var ErrInvalidTagRewrite = errors.New("invalid tag rewrite")
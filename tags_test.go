@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseTagRewrites(t *testing.T) {
+	for n, test := range [...]struct {
+		input    string
+		rewrites map[string]string
+		err      error
+	}{
+		{"", nil, nil},
+		{"-", map[string]string{}, nil},
+		{"json", map[string]string{"json": "json"}, nil},
+		{"json,xml=yaml", map[string]string{"json": "json", "xml": "yaml"}, nil},
+		{"json,", nil, ErrInvalidTagRewrite},
+		{"json=", nil, ErrInvalidTagRewrite},
+		{"a:b", nil, ErrInvalidTagRewrite},
+	} {
+		rewrites, err := parseTagRewrites(test.input)
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if !reflect.DeepEqual(rewrites, test.rewrites) {
+			t.Errorf("test %d: expecting rewrites %v, got %v", n+1, test.rewrites, rewrites)
+		}
+	}
+}
@@ -1,13 +1,21 @@
+// Package unsafe is a program that localises a type from another package.
 package main
 
 import (
+	"bytes"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"vimagination.zapto.org/unsafe/generator"
 )
 
+const stdout = "-"
+
 func main() {
 	if err := run(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -16,68 +24,332 @@ func main() {
 }
 
 func run() error {
+	if len(os.Args) > 1 && os.Args[1] == "inspect" {
+		return runInspect(os.Stdout, os.Args[2:])
+	}
+
+	return runGenerate(os.Stdout, os.Args[1:])
+}
+
+func runGenerate(w io.Writer, args []string) error {
 	var (
 		output, packageName string
+		dir                 string
+		config              string
+		tags                string
+		platformList        string
+		goVersionList       string
+		toolVersion         string
+		exclude             string
+		naming              string
+		renameList          string
 		excludeComment      bool
+		check               bool
+		accessors           bool
+		internal            bool
 	)
 
-	flag.StringVar(&output, "o", "", "output file")
-	flag.StringVar(&packageName, "p", "", "package name")
-	flag.BoolVar(&excludeComment, "x", false, "don't include go:generate comment")
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 
-	flag.Parse()
+	fs.StringVar(&output, "o", "", "output file, or - for stdout")
+	fs.StringVar(&dir, "dir", "", "directory of the package to generate for; defaults to the directory of the output file")
+	fs.StringVar(&packageName, "p", "", "package name")
+	fs.BoolVar(&excludeComment, "x", false, "don't include go:generate comment")
+	fs.StringVar(&toolVersion, "v", "", "version of this tool to run in the go:generate comment; 'current' for the running version, or 'tool' to use 'go tool'")
+	fs.StringVar(&tags, "t", "", "struct tag keys to keep, as a comma-separated list of key or key=newkey; - to strip all tags")
+	fs.StringVar(&exclude, "exclude", "", "comma-separated list of types, or type patterns, to exclude")
+	fs.StringVar(&naming, "naming", "", "naming scheme for localised types: full, short, camel, or a template, e.g. {{.Pkg}}{{title .Type}}")
+	fs.StringVar(&renameList, "rename", "", "comma-separated list of orig=Local renames for localised types")
+	fs.BoolVar(&accessors, "a", false, "generate getter and setter methods for all fields")
+	fs.BoolVar(&internal, "i", false, "allow localising types from internal and vendored packages")
+	fs.StringVar(&platformList, "platforms", "", "comma-separated list of GOOS/GOARCH pairs to generate separate outputs for")
+	fs.StringVar(&goVersionList, "go", "", "comma-separated list of Go toolchain versions, each optionally followed by =GOROOT, to generate separate outputs for")
+	fs.BoolVar(&check, "check", false, "check that the output file is up-to-date, printing a diff if it is not")
 
-	if output == "" {
+	fs.StringVar(&config, "c", "", "config file listing outputs to generate, replacing all other flags")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if config != "" {
+		return runConfig(w, config, check)
+	} else if output == "" {
 		return ErrNoOutput
 	}
 
-	var args []string
+	tagRewrites, err := parseTagRewrites(tags)
+	if err != nil {
+		return err
+	}
+
+	platforms, err := parsePlatforms(platformList)
+	if err != nil {
+		return err
+	}
+
+	goVersions, err := parseGoVersions(goVersionList)
+	if err != nil {
+		return err
+	}
+
+	renames, err := parseRenames(renameList)
+	if err != nil {
+		return err
+	}
+
+	namingOpts, err := namingOptions(naming, renames)
+	if err != nil {
+		return err
+	}
+
+	if output == stdout {
+		if check {
+			return ErrCheckStdout
+		} else if len(platforms) > 0 || len(goVersions) > 0 {
+			return ErrMultipleStdout
+		}
+	}
+
+	var (
+		opts      = append([]generator.Option{generator.PackageName(packageName), generator.TagRewrites(tagRewrites)}, namingOpts...)
+		directive generator.Option
+	)
+
+	if accessors {
+		opts = append(opts, generator.Accessors())
+	}
+
+	if internal {
+		opts = append(opts, generator.Internal())
+	}
+
+	if exclude != "" {
+		opts = append(opts, generator.Exclude(splitPatterns(exclude)...))
+	}
+
+	if !excludeComment && output != stdout {
+		version, err := resolveVersion(toolVersion)
+		if err != nil {
+			return err
+		}
 
-	if !excludeComment {
-		args = []string{"-o", filepath.Base(output)}
+		args := []string{"-o", filepath.Base(output)}
+
+		if dir != "" {
+			rel, err := relativeDir(filepath.Dir(output), dir)
+			if err != nil {
+				return err
+			}
+
+			if rel != "." {
+				args = append(args, "-dir", rel)
+			}
+		}
+
+		switch version {
+		case "":
+		case "tool":
+			opts = append(opts, generator.GoTool())
+			args = append(args, "-v", version)
+		default:
+			opts = append(opts, generator.ToolVersion(version))
+			args = append(args, "-v", version)
+		}
 
 		if packageName != "" {
 			args = append(args, "-p", packageName)
 		}
 
-		args = append(args, flag.Args()...)
+		if accessors {
+			args = append(args, "-a")
+		}
+
+		if internal {
+			args = append(args, "-i")
+		}
+
+		if tags != "" {
+			args = append(args, "-t", tags)
+		}
+
+		if exclude != "" {
+			args = append(args, "-exclude", exclude)
+		}
+
+		if naming != "" {
+			args = append(args, "-naming", naming)
+		}
+
+		if renameList != "" {
+			args = append(args, "-rename", renameList)
+		}
+
+		if platformList != "" {
+			args = append(args, "-platforms", platformList)
+		}
+
+		if goVersionList != "" {
+			args = append(args, "-go", goVersionList)
+		}
+
+		directive = generator.GenerateComment(append(args, fs.Args()...)...)
+
+		if len(platforms) == 0 && len(goVersions) == 0 {
+			opts = append(opts, directive)
+			directive = nil
+		}
+	}
+
+	err = generateVersions(w, dir, output, goVersions, platforms, check, fs.Args(), opts...)
+	if directive != nil && (err == nil || errors.Is(err, ErrOutdated)) {
+		if derr := generateDirective(w, dir, output, check, append(opts, directive)...); derr != nil {
+			return derr
+		}
 	}
 
-	absPath, err := filepath.Abs(filepath.Dir(output))
+	return err
+}
+
+func generate(w io.Writer, dir, output string, check bool, typeNames []string, opts ...generator.Option) error {
+	g, err := newGenerator(dir, output, opts...)
 	if err != nil {
 		return err
 	}
 
-	b, err := newBuilder(absPath, args...)
+	if output == stdout && !check {
+		return g.WriteType(w, typeNames...)
+	}
+
+	generated, err := g.GenerateOutput(typeNames...)
 	if err != nil {
 		return err
 	}
 
-	f := fileWriter{path: output}
+	// Padded output is only valid for the host architecture, so, as with
+	// -platforms, it is written to a file suffixed with that architecture,
+	// leaving the go:generate comment in an unconstrained file.
+	if generated.PaddedArch != "" {
+		if directive, derr := g.Directive(); derr == nil {
+			err = writeOutput(w, addSuffix(output, "_"+generated.PaddedArch), check, generated.Source)
+			if err == nil || errors.Is(err, ErrOutdated) {
+				if derr := writeOutput(w, output, check, directive); derr != nil {
+					return derr
+				}
+			}
+
+			return err
+		}
+	}
+
+	return writeOutput(w, output, check, generated.Source)
+}
+
+// generateDirective writes a file containing only the go:generate comment to
+// the output path, as go generate would not see the comment in the platform
+// or version specific outputs on other hosts.
+func generateDirective(w io.Writer, dir, output string, check bool, opts ...generator.Option) error {
+	g, err := newGenerator(dir, output, opts...)
+	if err != nil {
+		return err
+	}
 
-	if err := b.WriteType(&f, packageName, flag.Args()...); err != nil {
+	generated, err := g.Directive()
+	if err != nil {
 		return err
 	}
 
-	return f.Close()
+	return writeOutput(w, output, check, generated)
 }
 
-type fileWriter struct {
-	path string
-	*os.File
+func newGenerator(dir, output string, opts ...generator.Option) (*generator.Generator, error) {
+	if dir == "" {
+		dir = filepath.Dir(output)
+	}
+
+	absPath, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return generator.New(absPath, opts...)
 }
 
-func (f *fileWriter) Write(p []byte) (int, error) {
-	if f.File == nil {
-		var err error
+func writeOutput(w io.Writer, output string, check bool, generated []byte) error {
+	if check {
+		return checkOutput(w, output, generated)
+	}
 
-		f.File, err = os.Create(f.path)
-		if err != nil {
-			return 0, err
+	return writeFile(output, generated)
+}
+
+func checkOutput(w io.Writer, output string, generated []byte) error {
+	existing, err := os.ReadFile(output)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if bytes.Equal(existing, generated) {
+		return nil
+	}
+
+	if err := unifiedDiff(w, output, string(existing), string(generated)); err != nil {
+		return err
+	}
+
+	return ErrOutdated
+}
+
+// splitPatterns splits a comma-separated list of types and patterns, ignoring
+// commas within type arguments, glob character classes, and regular
+// expressions.
+func splitPatterns(list string) []string {
+	var (
+		patterns []string
+		start    int
+		depth    int
+		inRegexp bool
+	)
+
+	for n := 0; n < len(list); n++ {
+		switch c := list[n]; {
+		case inRegexp:
+			inRegexp = c != '/' || n+1 < len(list) && list[n+1] != ','
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == ',' && depth <= 0:
+			patterns = append(patterns, list[start:n])
+			start = n + 1
+			depth = 0
+		case c == '.' && strings.HasPrefix(list[n+1:], "/"):
+			inRegexp = true
+			n++
 		}
 	}
 
-	return f.File.Write(p)
+	return append(patterns, list[start:])
+}
+
+func relativeDir(from, to string) (string, error) {
+	absFrom, err := filepath.Abs(from)
+	if err != nil {
+		return "", err
+	}
+
+	absTo, err := filepath.Abs(to)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Rel(absFrom, absTo)
 }
 
-var ErrNoOutput = errors.New("no output file specified")
+var (
+	ErrNoOutput       = errors.New("no output file specified")
+	ErrNoTypes        = errors.New("no types specified")
+	ErrOutdated       = errors.New("output file is out of date")
+	ErrCheckStdout    = errors.New("cannot check output written to stdout")
+	ErrMultipleStdout = errors.New("cannot write multiple outputs to stdout")
+)
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"go/build"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"vimagination.zapto.org/unsafe/generator"
+)
+
+func TestRunGenerate(t *testing.T) {
+	dir := buildPackage(t)
+
+	for n, test := range [...]struct {
+		args     []string
+		contains string
+		err      error
+	}{
+		{[]string{"strings.Reader"}, "", ErrNoOutput},
+		{[]string{"-o", "-", "-check", "strings.Reader"}, "", ErrCheckStdout},
+		{[]string{"-o", "-", "-platforms", "linux/386", "strings.Reader"}, "", ErrMultipleStdout},
+		{[]string{"-o", "-", "-go", "go1.24", "strings.Reader"}, "", ErrMultipleStdout},
+		{[]string{"-o", "-", "-dir", dir, "strings.Reader"}, "type strings_Reader struct", nil},
+		{[]string{"-o", "-", "-dir", dir, "-v", "v1.2.3", "-p", "b", "strings.Reader"}, "package b", nil},
+		{[]string{"-o", "-", "-dir", dir, "-rename", "strings.Reader=strings", "strings.Reader"}, "", generator.ErrNameCollision},
+		{[]string{"-o", "-", "-dir", dir, "-rename", "strings.Reader=int", "strings.Reader"}, "", ErrInvalidRename},
+		{[]string{"-o", filepath.Join(dir, "b.go"), "-x", "-check", "strings.Reader"}, "+type strings_Reader struct", ErrOutdated},
+	} {
+		var buf strings.Builder
+
+		if err := runGenerate(&buf, test.args); !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if output := buf.String(); !strings.Contains(output, test.contains) {
+			t.Errorf("test %d: expecting output to contain %q, got:\n%s", n+1, test.contains, output)
+		} else if strings.Contains(output, "go:generate") {
+			t.Errorf("test %d: expecting no go:generate comment in output:\n%s", n+1, output)
+		}
+	}
+}
+
+func TestRunGeneratePadded(t *testing.T) {
+	var (
+		dir    = buildPackage(t)
+		output = filepath.Join(dir, "b.go")
+		padded = filepath.Join(dir, "b_"+build.Default.GOARCH+".go")
+	)
+
+	if err := runGenerate(io.Discard, []string{"-o", output, "strings.Reader{i}"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if data, err := os.ReadFile(output); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if str := string(data); !strings.HasPrefix(str, "//go:generate ") || strings.Contains(str, "//go:build") {
+		t.Errorf("expecting only a go:generate comment, got:\n%s", str)
+	}
+
+	if data, err := os.ReadFile(padded); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if str := string(data); !strings.HasPrefix(str, "//go:build "+build.Default.GOARCH+"\n") || strings.Contains(str, "//go:generate") {
+		t.Errorf("expecting a build constraint and no go:generate comment, got:\n%s", str)
+	}
+
+	if err := runGenerate(io.Discard, []string{"-o", output, "-check", "strings.Reader{i}"}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+// buildPackage writes a module, containing a package that imports the strings
+// package, to a temporary directory.
+func buildPackage(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module a\n\ngo 1.25.5\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\nimport _ \"strings\"\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return dir
+}
+
+func TestSplitPatterns(t *testing.T) {
+	for n, test := range [...]struct {
+		input    string
+		patterns []string
+	}{
+		{"strings.Reader", []string{"strings.Reader"}},
+		{"strings.Reader,net.conn*", []string{"strings.Reader", "net.conn*"}},
+		{"net./^a{1,3}$/,strings.Reader", []string{"net./^a{1,3}$/", "strings.Reader"}},
+		{"net/http./^(Client|Server)$/,net./a,b/", []string{"net/http./^(Client|Server)$/", "net./a,b/"}},
+		{"a.b[int,string],net.conn[,]*", []string{"a.b[int,string]", "net.conn[,]*"}},
+	} {
+		if patterns := splitPatterns(test.input); !reflect.DeepEqual(patterns, test.patterns) {
+			t.Errorf("test %d: expecting patterns %q, got %q", n+1, test.patterns, patterns)
+		}
+	}
+}
+
+func TestRelativeDir(t *testing.T) {
+	for n, test := range [...]struct {
+		from, to, rel string
+	}{
+		{".", ".", "."},
+		{"a", "a/", "."},
+		{"a", "b", "../b"},
+		{"a/b", "a", ".."},
+		{".", "a/b", "a/b"},
+	} {
+		if rel, err := relativeDir(test.from, test.to); err != nil {
+			t.Errorf("test %d: unexpected error: %s", n+1, err)
+		} else if rel != filepath.FromSlash(test.rel) {
+			t.Errorf("test %d: expecting relative dir %q, got %q", n+1, test.rel, rel)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"vimagination.zapto.org/unsafe/generator"
+)
+
+func resolveVersion(version string) (string, error) {
+	switch version {
+	case "", "tool":
+		return version, nil
+	case "current":
+		if version = buildVersion(); version == "" {
+			return "", ErrNoBuildVersion
+		}
+
+		return version, nil
+	}
+
+	if !isVersionQuery(version) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidVersion, version)
+	}
+
+	return version, nil
+}
+
+// isVersionQuery reports whether the version is a module version, or a
+// revision or branch name, that can be safely written to a go:generate
+// comment.
+func isVersionQuery(version string) bool {
+	if strings.HasPrefix(version, "-") {
+		return false
+	}
+
+	for _, c := range version {
+		if !isVersionChar(c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isVersionChar(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune(".-_+/", c)
+}
+
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	if info.Main.Path == generator.ToolPath {
+		return moduleVersion(&info.Main)
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == generator.ToolPath {
+			return moduleVersion(dep)
+		}
+	}
+
+	return ""
+}
+
+func moduleVersion(mod *debug.Module) string {
+	if mod.Replace != nil || mod.Version == "(devel)" || strings.HasSuffix(mod.Version, "+dirty") {
+		return ""
+	}
+
+	return mod.Version
+}
+
+var (
+	ErrNoBuildVersion = errors.New("could not determine version of running tool")
+	ErrInvalidVersion = errors.New("invalid version")
+)
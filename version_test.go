@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolveVersion(t *testing.T) {
+	for n, test := range [...]struct {
+		input, output string
+		err           error
+	}{
+		{"", "", nil},
+		{"tool", "tool", nil},
+		{"latest", "latest", nil},
+		{"v1.2.3", "v1.2.3", nil},
+		{"master", "master", nil},
+		{"v1.2.3 -x", "", ErrInvalidVersion},
+		{"unsafe@v1.2.3", "", ErrInvalidVersion},
+		{"v1.2.3-pre+meta", "v1.2.3-pre+meta", nil},
+		{"feature/branch", "feature/branch", nil},
+		{"v1.2.3\n//go:generate rm -rf .", "", ErrInvalidVersion},
+		{"v1.2.3\rx", "", ErrInvalidVersion},
+		{"v1.2.3;x", "", ErrInvalidVersion},
+		{"-x", "", ErrInvalidVersion},
+		{"current", buildVersion(), nil},
+	} {
+		if test.input == "current" && test.output == "" {
+			test.err = ErrNoBuildVersion
+		}
+
+		if output, err := resolveVersion(test.input); !errors.Is(err, test.err) {
+			t.Errorf("test %d: expecting error %v, got %v", n+1, test.err, err)
+		} else if output != test.output {
+			t.Errorf("test %d: expecting output %q, got %q", n+1, test.output, output)
+		}
+	}
+}